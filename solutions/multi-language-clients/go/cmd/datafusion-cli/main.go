@@ -0,0 +1,184 @@
+// datafusion-cli 是执行 DataFusion 即席查询的命令行工具。
+//
+// 用法:
+//
+//	datafusion-cli --server db.example.com:443 --sql "SELECT 1"
+//	datafusion-cli --server localhost:50051 --insecure --sql "SELECT 1"
+//	echo "SELECT 1" | datafusion-cli --format csv
+//	datafusion-cli --sql "SELECT * FROM t" --format ndjson | jq .
+//	datafusion-cli --sql "SELECT * FROM t" --dry-run
+//	datafusion-cli -i
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"datafusion-client/datafusion"
+)
+
+// 退出码
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// querier 是命令行工具依赖的客户端能力
+type querier interface {
+	datafusion.Querier
+	io.Closer
+}
+
+// estimator 是 --dry-run 依赖的代价估算能力
+type estimator interface {
+	EstimateCost(ctx context.Context, sql string) (*datafusion.CostEstimate, error)
+}
+
+// dialFunc 根据命令行配置创建客户端
+type dialFunc func(ctx context.Context, cfg *config) (querier, error)
+
+// config 是解析后的命令行参数
+type config struct {
+	server      string
+	format      datafusion.ResultFormat
+	timeout     time.Duration
+	sql         string
+	interactive bool
+	insecure    bool
+	dryRun      bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, dial))
+}
+
+func dial(ctx context.Context, cfg *config) (querier, error) {
+	opts := []datafusion.Option{datafusion.WithUserAgent("datafusion-cli")}
+	if cfg.insecure {
+		opts = append(opts, datafusion.WithInsecure())
+	}
+	return datafusion.NewClient(ctx, cfg.server, opts...)
+}
+
+// parseFlags 解析命令行参数
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	fs := flag.NewFlagSet("datafusion-cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	cfg := &config{}
+	var format string
+	fs.StringVar(&cfg.server, "server", "localhost:50051", "服务地址")
+	fs.StringVar(&format, "format", "table", "输出格式: table、json、ndjson 或 csv")
+	fs.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "单条查询的超时时间")
+	fs.StringVar(&cfg.sql, "sql", "", "要执行的 SQL，未指定时从标准输入读取")
+	fs.BoolVar(&cfg.interactive, "i", false, "交互模式，语句以分号结束")
+	fs.BoolVar(&cfg.insecure, "insecure", false, "使用明文连接，默认使用 TLS")
+	fs.BoolVar(&cfg.dryRun, "dry-run", false, "只估算查询代价，不执行")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("多余的参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if cfg.interactive && cfg.sql != "" {
+		return nil, errors.New("-i 与 --sql 不能同时使用")
+	}
+
+	var err error
+	if cfg.format, err = datafusion.ParseResultFormat(format); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, dial dialFunc) int {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "参数错误: %v\n", err)
+		return exitUsage
+	}
+
+	ctx := context.Background()
+	client, err := dial(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "连接失败: %v\n", err)
+		return exitError
+	}
+	defer client.Close()
+
+	if cfg.interactive {
+		repl(ctx, client, cfg, stdin, stdout, stderr)
+		return exitOK
+	}
+
+	statements := []string{cfg.sql}
+	if cfg.sql == "" {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "读取标准输入失败: %v\n", err)
+			return exitError
+		}
+		var rest string
+		statements, rest = splitStatements(string(input))
+		if rest = strings.TrimSpace(rest); rest != "" {
+			statements = append(statements, rest)
+		}
+	}
+	if len(statements) == 0 {
+		fmt.Fprintln(stderr, "参数错误: 没有要执行的 SQL")
+		return exitUsage
+	}
+
+	for _, sql := range statements {
+		if err := execute(ctx, client, cfg, sql, stdout); err != nil {
+			fmt.Fprintf(stderr, "错误: %v\n", err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// execute 在超时限制内执行一条语句并输出结果
+func execute(ctx context.Context, client querier, cfg *config, sql string, stdout io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	if cfg.dryRun {
+		e, ok := client.(estimator)
+		if !ok {
+			return errors.New("客户端不支持代价估算")
+		}
+		est, err := e.EstimateCost(ctx, sql)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, est)
+		return err
+	}
+
+	if cfg.format == datafusion.FormatNDJSON {
+		// 边接收边输出，便于管道中的下游工具及时处理
+		stream, err := client.ExecuteQueryStream(ctx, sql)
+		if err != nil {
+			return err
+		}
+		return datafusion.WriteNDJSON(stdout, stream)
+	}
+
+	resp, err := client.ExecuteQuery(ctx, sql)
+	if err != nil {
+		return err
+	}
+	return datafusion.FormatResult(stdout, resp, cfg.format)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"datafusion-client/datafusion"
+	"datafusion-client/datafusion/clienttest"
+)
+
+// fakeClient 为 clienttest.FakeQuerier 补上 Close
+type fakeClient struct {
+	clienttest.FakeQuerier
+	closed bool
+}
+
+func (f *fakeClient) Close() error {
+	f.closed = true
+	return nil
+}
+
+// runWith 用 client 执行 run，返回退出码、标准输出和标准错误
+func runWith(t *testing.T, client querier, args []string, stdin string) (int, string, string, *config) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	var got *config
+	dial := func(ctx context.Context, cfg *config) (querier, error) {
+		got = cfg
+		return client, nil
+	}
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr, dial)
+	return code, stdout.String(), stderr.String(), got
+}
+
+func TestParseFlagsDefaults(t *testing.T) {
+	cfg, err := parseFlags(nil, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	if cfg.server != "localhost:50051" {
+		t.Errorf("server = %q", cfg.server)
+	}
+	if cfg.format != datafusion.FormatTable {
+		t.Errorf("format = %v, want table", cfg.format)
+	}
+	if cfg.insecure {
+		t.Error("默认应使用 TLS 连接")
+	}
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"未知格式", []string{"--format", "xml"}},
+		{"多余参数", []string{"--sql", "SELECT 1", "extra"}},
+		{"交互模式与 sql 同时使用", []string{"-i", "--sql", "SELECT 1"}},
+		{"未知参数", []string{"--nope"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr, _ := runWith(t, &fakeClient{}, tt.args, "")
+			if code != exitUsage {
+				t.Errorf("退出码 = %d, want %d (stderr: %s)", code, exitUsage, stderr)
+			}
+		})
+	}
+}
+
+func TestRunSQLFlag(t *testing.T) {
+	client := &fakeClient{}
+	client.SetResponse("SELECT 1", &datafusion.QueryResponse{
+		Columns: []datafusion.Column{{Name: "x"}},
+		Rows:    []datafusion.Row{{int64(1)}},
+	})
+
+	code, stdout, stderr, cfg := runWith(t, client, []string{"--insecure", "--format", "csv", "--sql", "SELECT 1"}, "")
+	if code != exitOK {
+		t.Fatalf("退出码 = %d, stderr: %s", code, stderr)
+	}
+	if stdout != "x\n1\n" {
+		t.Errorf("输出 = %q", stdout)
+	}
+	if !cfg.insecure {
+		t.Error("--insecure 未生效")
+	}
+	if !client.closed {
+		t.Error("客户端未关闭")
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	client := &fakeClient{}
+	client.SetResponse("SELECT 1", &datafusion.QueryResponse{Result: "one"})
+	client.SetResponse("SELECT ';'", &datafusion.QueryResponse{Result: "two"})
+	client.SetResponse("SELECT 3", &datafusion.QueryResponse{Result: "three"})
+
+	code, stdout, stderr, _ := runWith(t, client, nil, "SELECT 1;\nSELECT ';';\nSELECT 3\n")
+	if code != exitOK {
+		t.Fatalf("退出码 = %d, stderr: %s", code, stderr)
+	}
+	if stdout != "one\ntwo\nthree\n" {
+		t.Errorf("输出 = %q", stdout)
+	}
+	var sqls []string
+	for _, call := range client.Calls() {
+		sqls = append(sqls, call.SQL)
+	}
+	if got := strings.Join(sqls, "|"); got != "SELECT 1|SELECT ';'|SELECT 3" {
+		t.Errorf("执行的语句 = %s", got)
+	}
+}
+
+func TestRunEmptyStdin(t *testing.T) {
+	code, _, _, _ := runWith(t, &fakeClient{}, nil, "  \n")
+	if code != exitUsage {
+		t.Errorf("退出码 = %d, want %d", code, exitUsage)
+	}
+}
+
+func TestRunQueryError(t *testing.T) {
+	client := &fakeClient{}
+	client.SetResponse("SELECT 1", &datafusion.QueryResponse{Result: "one"})
+
+	code, stdout, stderr, _ := runWith(t, client, nil, "SELECT 1; SELECT missing; SELECT 1;")
+	if code != exitError {
+		t.Fatalf("退出码 = %d, want %d", code, exitError)
+	}
+	if stdout != "one\n" {
+		t.Errorf("出错后不应继续执行: %q", stdout)
+	}
+	if !strings.Contains(stderr, "错误") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestRunNDJSONUsesStream(t *testing.T) {
+	client := &fakeClient{}
+	client.SetStream("SELECT x", &datafusion.RowBatch{
+		Columns: []datafusion.Column{{Name: "x"}},
+		Rows:    []datafusion.Row{{int64(1)}, {int64(2)}},
+	})
+
+	code, stdout, stderr, _ := runWith(t, client, []string{"--format", "ndjson", "--sql", "SELECT x"}, "")
+	if code != exitOK {
+		t.Fatalf("退出码 = %d, stderr: %s", code, stderr)
+	}
+	if stdout != "{\"x\":1}\n{\"x\":2}\n" {
+		t.Errorf("输出 = %q", stdout)
+	}
+	if calls := client.Calls(); len(calls) != 1 || calls[0].Method != "ExecuteQueryStream" {
+		t.Errorf("调用 = %+v", calls)
+	}
+}
+
+func TestRunInteractive(t *testing.T) {
+	client := &fakeClient{}
+	client.SetResponse("SELECT\n1", &datafusion.QueryResponse{Result: "one"})
+
+	code, stdout, stderr, _ := runWith(t, client, []string{"-i"}, "SELECT\n1;\n")
+	if code != exitOK {
+		t.Fatalf("退出码 = %d, stderr: %s", code, stderr)
+	}
+	want := prompt + continuePrompt + "one\n" + prompt + "\n"
+	if stdout != want {
+		t.Errorf("输出 = %q, want %q", stdout, want)
+	}
+}
+
+// estimatingClient 在 fakeClient 基础上支持代价估算
+type estimatingClient struct {
+	fakeClient
+	estimates map[string]*datafusion.CostEstimate
+}
+
+func (c *estimatingClient) EstimateCost(ctx context.Context, sql string) (*datafusion.CostEstimate, error) {
+	return c.estimates[sql], nil
+}
+
+func TestRunDryRun(t *testing.T) {
+	client := &estimatingClient{estimates: map[string]*datafusion.CostEstimate{
+		"SELECT * FROM events": {Known: true, RowsScanned: 1000, Partitions: 4, Cost: 12.5},
+	}}
+
+	code, stdout, stderr, _ := runWith(t, client, []string{"--dry-run", "--sql", "SELECT * FROM events"}, "")
+	if code != exitOK {
+		t.Fatalf("退出码 = %d, stderr: %s", code, stderr)
+	}
+	if stdout != "预计扫描 1000 行，涉及 4 个分区，代价 12.50\n" {
+		t.Errorf("输出 = %q", stdout)
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("--dry-run 不应执行查询: %+v", calls)
+	}
+}
+
+func TestRunDryRunUnsupported(t *testing.T) {
+	code, _, stderr, _ := runWith(t, &fakeClient{}, []string{"--dry-run", "--sql", "SELECT 1"}, "")
+	if code != exitError || !strings.Contains(stderr, "代价估算") {
+		t.Errorf("退出码 = %d, stderr = %q", code, stderr)
+	}
+}
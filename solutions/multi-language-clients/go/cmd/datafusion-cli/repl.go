@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	prompt         = "datafusion> "
+	continuePrompt = "         -> "
+)
+
+// repl 循环读取以分号结束的语句并执行，直到输入结束
+func repl(ctx context.Context, client querier, cfg *config, stdin io.Reader, stdout, stderr io.Writer) {
+	scanner := bufio.NewScanner(stdin)
+	var pending strings.Builder
+
+	fmt.Fprint(stdout, prompt)
+	for scanner.Scan() {
+		pending.WriteString(scanner.Text())
+		pending.WriteString("\n")
+
+		statements, rest := splitStatements(pending.String())
+		pending.Reset()
+		pending.WriteString(rest)
+
+		for _, sql := range statements {
+			if err := execute(ctx, client, cfg, sql, stdout); err != nil {
+				fmt.Fprintf(stderr, "错误: %v\n", err)
+			}
+		}
+
+		if strings.TrimSpace(rest) == "" {
+			pending.Reset()
+			fmt.Fprint(stdout, prompt)
+		} else {
+			fmt.Fprint(stdout, continuePrompt)
+		}
+	}
+	fmt.Fprintln(stdout)
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "读取输入失败: %v\n", err)
+	}
+}
+
+// splitStatements 按分号切分出完整的语句，忽略引号内的分号。
+// 返回去掉结尾分号的语句和最后一段未结束的输入。
+func splitStatements(input string) (statements []string, rest string) {
+	var quote rune
+	start := 0
+	for i, r := range input {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';':
+			if sql := strings.TrimSpace(input[start:i]); sql != "" {
+				statements = append(statements, sql)
+			}
+			start = i + 1
+		}
+	}
+	return statements, input[start:]
+}
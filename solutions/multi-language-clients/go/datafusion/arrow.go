@@ -0,0 +1,118 @@
+package datafusion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+
+	"datafusion-client/pb"
+)
+
+// ExecuteQueryArrow 执行查询并以 Arrow 记录批的形式返回结果。
+// 服务端返回多个批次时会合并为一个记录。调用方负责 Release 返回的记录。
+func (c *DataFusionClient) ExecuteQueryArrow(ctx context.Context, sql string) (arrow.Record, error) {
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	var rec arrow.Record
+	err = c.instrument(ctx, sql, func(ctx context.Context) (int, error) {
+		resp, _, err := c.executeQuery(ctx, &pb.QueryRequest{
+			Sql:      sql,
+			Encoding: pb.ResultEncoding_RESULT_ENCODING_ARROW_IPC,
+		})
+		if err != nil {
+			return 0, newQueryError(ctx, sql, err)
+		}
+		if rec, err = decodeArrowIPC(resp.GetArrowIpc()); err != nil {
+			return 0, err
+		}
+		return int(rec.NumRows()), nil
+	})
+	return rec, err
+}
+
+// decodeArrowIPC 将 Arrow IPC 流解码为单个记录
+func decodeArrowIPC(payload []byte) (arrow.Record, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("响应中没有 Arrow 数据")
+	}
+
+	mem := memory.DefaultAllocator
+	r, err := ipc.NewReader(bytes.NewReader(payload), ipc.WithAllocator(mem))
+	if err != nil {
+		return nil, fmt.Errorf("解析 Arrow IPC 流失败: %w", err)
+	}
+	defer r.Release()
+
+	var recs []arrow.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 Arrow 记录失败: %w", err)
+		}
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+
+	switch len(recs) {
+	case 0:
+		b := array.NewRecordBuilder(mem, r.Schema())
+		defer b.Release()
+		return b.NewRecord(), nil
+	case 1:
+		recs[0].Retain()
+		return recs[0], nil
+	}
+
+	// 按列合并多个批次
+	schema := r.Schema()
+	cols := make([]arrow.Array, schema.NumFields())
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+	var rows int64
+	for _, rec := range recs {
+		rows += rec.NumRows()
+	}
+	for i := range cols {
+		parts := make([]arrow.Array, len(recs))
+		for j, rec := range recs {
+			parts[j] = rec.Column(i)
+		}
+		col, err := array.Concatenate(parts, mem)
+		if err != nil {
+			return nil, fmt.Errorf("合并列 %s 失败: %w", schema.Field(i).Name, err)
+		}
+		cols[i] = col
+	}
+	return array.NewRecord(schema, cols, rows), nil
+}
@@ -0,0 +1,70 @@
+package datafusion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+
+	"datafusion-client/pb"
+)
+
+func TestExecuteQueryArrowRoundTrip(t *testing.T) {
+	alice, carol := "alice", "carol"
+	var encoding pb.ResultEncoding
+	srv := &fakeServer{
+		executeQuery: func(_ context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+			encoding = req.GetEncoding()
+			return &pb.QueryResponse{ArrowIpc: arrowIPC(t, []int64{1, 2, 3}, []*string{&alice, nil, &carol})}, nil
+		},
+	}
+	c := newTestClient(t, srv)
+
+	rec, err := c.ExecuteQueryArrow(context.Background(), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("ExecuteQueryArrow: %v", err)
+	}
+	defer rec.Release()
+
+	if encoding != pb.ResultEncoding_RESULT_ENCODING_ARROW_IPC {
+		t.Errorf("请求编码 = %v, want ARROW_IPC", encoding)
+	}
+	schema := rec.Schema()
+	if schema.NumFields() != 2 ||
+		schema.Field(0).Name != "id" || !arrow.TypeEqual(schema.Field(0).Type, arrow.PrimitiveTypes.Int64) ||
+		schema.Field(1).Name != "name" || !arrow.TypeEqual(schema.Field(1).Type, arrow.BinaryTypes.String) {
+		t.Fatalf("schema = %v, want id: int64, name: utf8", schema)
+	}
+	if rec.NumRows() != 3 {
+		t.Fatalf("行数 = %d, want 3", rec.NumRows())
+	}
+
+	ids := rec.Column(0).(*array.Int64)
+	names := rec.Column(1).(*array.String)
+	for i, want := range []int64{1, 2, 3} {
+		if ids.Value(i) != want {
+			t.Errorf("id[%d] = %d, want %d", i, ids.Value(i), want)
+		}
+	}
+	if names.Value(0) != "alice" || names.Value(2) != "carol" {
+		t.Errorf("name = [%q %q], want [alice carol]", names.Value(0), names.Value(2))
+	}
+	if !names.IsNull(1) {
+		t.Errorf("name[1] = %q, want NULL", names.Value(1))
+	}
+}
+
+func TestExecuteQueryArrowEmptyPayload(t *testing.T) {
+	srv := &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{}, nil
+		},
+	}
+	c := newTestClient(t, srv)
+
+	if rec, err := c.ExecuteQueryArrow(context.Background(), "SELECT 1"); err == nil {
+		rec.Release()
+		t.Fatal("响应中没有 Arrow 数据时应返回错误")
+	}
+}
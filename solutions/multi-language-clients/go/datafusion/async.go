@@ -0,0 +1,195 @@
+package datafusion
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// WaitForResult 未指定轮询间隔时的默认值
+const defaultPollInterval = time.Second
+
+// QueryState 是后台查询的执行状态。
+type QueryState int
+
+const (
+	// QueryStateUnknown 表示服务端返回了无法识别的状态
+	QueryStateUnknown QueryState = iota
+	// QueryPending 表示查询在排队等待执行
+	QueryPending
+	// QueryRunning 表示查询正在执行
+	QueryRunning
+	// QuerySucceeded 表示查询已成功，可以取回结果
+	QuerySucceeded
+	// QueryFailed 表示查询失败
+	QueryFailed
+)
+
+func (s QueryState) String() string {
+	switch s {
+	case QueryPending:
+		return "pending"
+	case QueryRunning:
+		return "running"
+	case QuerySucceeded:
+		return "succeeded"
+	case QueryFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Done 判断状态是否为终态。
+func (s QueryState) Done() bool {
+	return s == QuerySucceeded || s == QueryFailed
+}
+
+// QueryStatus 是后台查询的当前状态。
+type QueryStatus struct {
+	QueryID string
+	State   QueryState
+	// Progress 是完成百分比 (0-100)，服务端无法估计时为 -1
+	Progress float64
+	// Err 是 State 为 QueryFailed 时的失败原因，类型为 *QueryError
+	Err error
+}
+
+// SubmitQuery 提交一条在服务端后台执行的查询并立即返回查询 ID，
+// 之后用 QueryStatus 查看进度、FetchResult 取回结果，
+// 适合不希望长时间占用连接的 ETL 查询。
+// 重复提交会在服务端启动多个后台查询，因此提交失败时不会自动重试。
+func (c *DataFusionClient) SubmitQuery(ctx context.Context, sql string) (string, error) {
+	if err := c.validate(sql); err != nil {
+		return "", err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	var resp *pb.SubmitQueryResponse
+	err = c.withRetry(ctx, false, func() error {
+		var err error
+		resp, err = c.rpc.SubmitQuery(ctx, &pb.SubmitQueryRequest{Sql: sql})
+		return err
+	})
+	if err != nil {
+		return "", newQueryError(ctx, sql, err)
+	}
+	return resp.GetQueryId(), nil
+}
+
+// QueryStatus 返回后台查询的状态。
+func (c *DataFusionClient) QueryStatus(ctx context.Context, queryID string) (*QueryStatus, error) {
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	var resp *pb.QueryStatusResponse
+	err = c.withRetry(ctx, true, func() error {
+		var err error
+		resp, err = c.rpc.GetQueryStatus(ctx, &pb.QueryStatusRequest{QueryId: queryID})
+		return err
+	})
+	if err != nil {
+		return nil, newQueryError(ctx, "", err)
+	}
+
+	st := &QueryStatus{
+		QueryID:  queryID,
+		State:    queryStateFromPB(resp.GetState()),
+		Progress: -1,
+	}
+	if resp.Progress != nil {
+		st.Progress = resp.GetProgress()
+	}
+	if st.State == QueryFailed {
+		code, msg := codes.Unknown, "后台查询失败"
+		if se := resp.GetError(); se != nil {
+			code, msg = codes.Code(se.GetCode()), se.GetMessage()
+		}
+		st.Err = newQueryError(ctx, "", status.Error(code, msg))
+	}
+	return st, nil
+}
+
+// FetchResult 返回已成功的后台查询的结果，查询尚未完成时返回 FailedPrecondition 错误。
+func (c *DataFusionClient) FetchResult(ctx context.Context, queryID string) (*QueryResponse, error) {
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	var resp *pb.QueryResponse
+	err = c.instrument(ctx, "", func(ctx context.Context) (int, error) {
+		err := c.withRetry(ctx, true, func() error {
+			var err error
+			resp, err = c.rpc.FetchResult(ctx, &pb.FetchResultRequest{QueryId: queryID})
+			return err
+		})
+		if err != nil {
+			return 0, newQueryError(ctx, "", err)
+		}
+		return len(resp.GetRows()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newQueryResponse(ctx, resp, queryID), nil
+}
+
+// WaitForResult 每隔 pollInterval 查询一次状态，直到查询结束或 ctx 结束。
+// 查询成功时返回结果，失败时返回 QueryStatus.Err。pollInterval 为 0 时每秒查询一次。
+func (c *DataFusionClient) WaitForResult(ctx context.Context, queryID string, pollInterval time.Duration) (*QueryResponse, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		st, err := c.QueryStatus(ctx, queryID)
+		if err != nil {
+			return nil, err
+		}
+		switch st.State {
+		case QuerySucceeded:
+			return c.FetchResult(ctx, queryID)
+		case QueryFailed:
+			return nil, st.Err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func queryStateFromPB(s pb.QueryState) QueryState {
+	switch s {
+	case pb.QueryState_QUERY_STATE_PENDING:
+		return QueryPending
+	case pb.QueryState_QUERY_STATE_RUNNING:
+		return QueryRunning
+	case pb.QueryState_QUERY_STATE_SUCCEEDED:
+		return QuerySucceeded
+	case pb.QueryState_QUERY_STATE_FAILED:
+		return QueryFailed
+	default:
+		return QueryStateUnknown
+	}
+}
@@ -0,0 +1,155 @@
+package datafusion
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// asyncServer 按 states 依次报告后台查询的状态，最后一个状态保持不变
+type asyncServer struct {
+	states     []pb.QueryState
+	submitCode codes.Code
+	submits    atomic.Int32
+	mu         sync.Mutex
+	polls      int
+	fetchedID  string
+	reported   []pb.QueryState
+}
+
+func (s *asyncServer) fake() *fakeServer {
+	return &fakeServer{
+		submitQuery:    s.submitQuery,
+		getQueryStatus: s.getQueryStatus,
+		fetchResult:    s.fetchResult,
+	}
+}
+
+func (s *asyncServer) submitQuery(ctx context.Context, req *pb.SubmitQueryRequest) (*pb.SubmitQueryResponse, error) {
+	s.submits.Add(1)
+	if s.submitCode != codes.OK {
+		return nil, status.Error(s.submitCode, "提交失败")
+	}
+	return &pb.SubmitQueryResponse{QueryId: "q-1"}, nil
+}
+
+func (s *asyncServer) getQueryStatus(ctx context.Context, req *pb.QueryStatusRequest) (*pb.QueryStatusResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.states[min(s.polls, len(s.states)-1)]
+	s.polls++
+	s.reported = append(s.reported, state)
+
+	resp := &pb.QueryStatusResponse{State: state}
+	switch state {
+	case pb.QueryState_QUERY_STATE_RUNNING:
+		progress := 50.0
+		resp.Progress = &progress
+	case pb.QueryState_QUERY_STATE_FAILED:
+		resp.Error = &pb.StatementError{Code: int32(codes.ResourceExhausted), Message: "内存不足"}
+	}
+	return resp, nil
+}
+
+func (s *asyncServer) fetchResult(ctx context.Context, req *pb.FetchResultRequest) (*pb.QueryResponse, error) {
+	s.mu.Lock()
+	s.fetchedID = req.GetQueryId()
+	s.mu.Unlock()
+	return &pb.QueryResponse{Result: "done"}, nil
+}
+
+func TestAsyncQueryLifecycle(t *testing.T) {
+	srv := &asyncServer{states: []pb.QueryState{
+		pb.QueryState_QUERY_STATE_PENDING,
+		pb.QueryState_QUERY_STATE_RUNNING,
+		pb.QueryState_QUERY_STATE_SUCCEEDED,
+	}}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	id, err := c.SubmitQuery(ctx, "INSERT INTO t SELECT * FROM s")
+	if err != nil {
+		t.Fatalf("SubmitQuery: %v", err)
+	}
+	if id != "q-1" {
+		t.Fatalf("查询 ID = %q, want q-1", id)
+	}
+
+	st, err := c.QueryStatus(ctx, id)
+	if err != nil {
+		t.Fatalf("QueryStatus: %v", err)
+	}
+	if st.State != QueryPending || st.Progress != -1 || st.State.Done() {
+		t.Errorf("首次状态 = %+v, want pending 且进度未知", st)
+	}
+
+	resp, err := c.WaitForResult(ctx, id, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+	if resp.Result != "done" || resp.QueryID != id {
+		t.Errorf("结果 = %+v", resp)
+	}
+
+	want := []pb.QueryState{
+		pb.QueryState_QUERY_STATE_PENDING,
+		pb.QueryState_QUERY_STATE_RUNNING,
+		pb.QueryState_QUERY_STATE_SUCCEEDED,
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.reported) != len(want) {
+		t.Fatalf("经历的状态 = %v, want %v", srv.reported, want)
+	}
+	for i := range want {
+		if srv.reported[i] != want[i] {
+			t.Fatalf("经历的状态 = %v, want %v", srv.reported, want)
+		}
+	}
+	if srv.fetchedID != id {
+		t.Errorf("FetchResult 的查询 ID = %q", srv.fetchedID)
+	}
+}
+
+func TestAsyncQueryFailure(t *testing.T) {
+	srv := &asyncServer{states: []pb.QueryState{
+		pb.QueryState_QUERY_STATE_RUNNING,
+		pb.QueryState_QUERY_STATE_FAILED,
+	}}
+	c := newTestClient(t, srv.fake())
+
+	_, err := c.WaitForResult(context.Background(), "q-1", time.Millisecond)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want ResourceExhausted", err)
+	}
+}
+
+func TestWaitForResultStopsOnContext(t *testing.T) {
+	srv := &asyncServer{states: []pb.QueryState{pb.QueryState_QUERY_STATE_RUNNING}}
+	c := newTestClient(t, srv.fake())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForResult(ctx, "q-1", 5*time.Millisecond); err == nil {
+		t.Fatal("ctx 结束后应返回错误")
+	}
+}
+
+func TestSubmitQueryIsNotRetried(t *testing.T) {
+	srv := &asyncServer{submitCode: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond))
+
+	if _, err := c.SubmitQuery(context.Background(), "SELECT 1"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+	if got := srv.submits.Load(); got != 1 {
+		t.Errorf("提交次数 = %d, want 1", got)
+	}
+}
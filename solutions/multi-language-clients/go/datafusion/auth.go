@@ -0,0 +1,63 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// WithTokenSource 为每次调用附加 authorization: Bearer <token> 元数据。
+// 令牌从 ts 获取并缓存，临近过期时自动刷新。
+// 令牌只通过安全连接发送，参见 AllowInsecureTokens。
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = oauth2.ReuseTokenSource(nil, ts)
+	}
+}
+
+// WithStaticToken 为每次调用附加固定的 Bearer 令牌。
+func WithStaticToken(token string) Option {
+	return func(o *options) {
+		o.tokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	}
+}
+
+// AllowInsecureTokens 允许在明文连接上发送令牌，仅用于本地开发。
+func AllowInsecureTokens() Option {
+	return func(o *options) {
+		o.allowInsecureTokens = true
+	}
+}
+
+// perRPCCredentials 根据配置构造每次调用的认证凭据，未配置令牌时返回 nil
+func (o *options) perRPCCredentials() (credentials.PerRPCCredentials, error) {
+	if o.tokenSource == nil {
+		return nil, nil
+	}
+	if o.insecure && !o.allowInsecureTokens {
+		return nil, errors.New("令牌不能通过明文连接发送，如确需发送请使用 AllowInsecureTokens")
+	}
+	return tokenCredentials{ts: o.tokenSource, requireSecurity: !o.allowInsecureTokens}, nil
+}
+
+// tokenCredentials 将 oauth2 令牌作为 authorization 元数据发送
+type tokenCredentials struct {
+	ts              oauth2.TokenSource
+	requireSecurity bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	tok, err := c.ts.Token()
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "获取访问令牌失败: %v", err)
+	}
+	return map[string]string{"authorization": tok.Type() + " " + tok.AccessToken}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.requireSecurity
+}
@@ -0,0 +1,135 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// countingTokenSource 每次调用返回新令牌 tok-1、tok-2 …，有效期为 ttl (可为负数)
+type countingTokenSource struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return &oauth2.Token{
+		AccessToken: fmt.Sprintf("tok-%d", s.calls),
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(s.ttl),
+	}, nil
+}
+
+func (s *countingTokenSource) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// authServer 记录每次调用收到的 authorization 元数据
+func authServer(got *[]string) *fakeServer {
+	var mu sync.Mutex
+	return &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			mu.Lock()
+			*got = append(*got, md.Get("authorization")...)
+			mu.Unlock()
+			return &pb.QueryResponse{}, nil
+		},
+	}
+}
+
+func TestWithStaticTokenSendsBearerHeader(t *testing.T) {
+	var got []string
+	c := newTestClient(t, authServer(&got), WithStaticToken("secret"), AllowInsecureTokens())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("authorization = %q, want [Bearer secret]", got)
+	}
+}
+
+func TestWithTokenSourceRefreshesExpiredToken(t *testing.T) {
+	var got []string
+	ts := &countingTokenSource{ttl: -time.Minute}
+	c := newTestClient(t, authServer(&got), WithTokenSource(ts), AllowInsecureTokens())
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+	want := []string{"Bearer tok-1", "Bearer tok-2"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("authorization = %q, want %q", got, want)
+	}
+	if ts.count() != 2 {
+		t.Errorf("令牌已过期时每次调用都应刷新，Token 调用 %d 次, want 2", ts.count())
+	}
+}
+
+func TestWithTokenSourceReusesValidToken(t *testing.T) {
+	var got []string
+	ts := &countingTokenSource{ttl: time.Hour}
+	c := newTestClient(t, authServer(&got), WithTokenSource(ts), AllowInsecureTokens())
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+	if ts.count() != 1 {
+		t.Errorf("令牌未过期时应复用，Token 调用 %d 次, want 1", ts.count())
+	}
+	for _, h := range got {
+		if h != "Bearer tok-1" {
+			t.Errorf("authorization = %q, want Bearer tok-1", h)
+		}
+	}
+}
+
+type failingTokenSource struct{}
+
+func (failingTokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("授权服务不可用")
+}
+
+func TestWithTokenSourceError(t *testing.T) {
+	var got []string
+	c := newTestClient(t, authServer(&got), WithTokenSource(failingTokenSource{}), AllowInsecureTokens())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ExecuteQuery = %v, want Unauthenticated", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("获取令牌失败时请求不应到达服务端")
+	}
+}
+
+func TestTokensRequireSecureTransport(t *testing.T) {
+	c, err := NewClient(context.Background(), "127.0.0.1:1", WithInsecure(), WithStaticToken("secret"))
+	if err == nil {
+		c.Close()
+		t.Fatal("明文连接上配置令牌时 NewClient 应返回错误")
+	}
+}
@@ -0,0 +1,85 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// BatchResult 是批量中单条语句的结果，Response 和 Err 只有一个非空。
+type BatchResult struct {
+	Response *QueryResponse
+	// Err 是该语句的 *QueryError
+	Err error
+}
+
+// ExecuteBatch 在一次往返中执行 sqls，结果顺序与输入一致。
+// 单条语句失败记录在对应的 BatchResult.Err 中，不影响其余语句；
+// 只有整个请求失败时才返回 error。
+// 配置了 WithBudgetPolicy 时逐条发送语句，每条语句单独计时。
+func (c *DataFusionClient) ExecuteBatch(ctx context.Context, sqls []string) ([]BatchResult, error) {
+	if len(sqls) == 0 {
+		return nil, nil
+	}
+	for _, sql := range sqls {
+		if err := c.validate(sql); err != nil {
+			return nil, err
+		}
+	}
+	if c.opts.budgetPolicy != budgetNone {
+		return c.executeBudgeted(ctx, sqls)
+	}
+
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	idempotent := true
+	for _, sql := range sqls {
+		idempotent = idempotent && isIdempotent(sql)
+	}
+
+	// 整个批次按一次查询记录，单条语句的失败不算作批次失败
+	var results []BatchResult
+	err = c.instrument(ctx, strings.Join(sqls, "; "), func(ctx context.Context) (int, error) {
+		var resp *pb.BatchResponse
+		err := c.withRetry(ctx, idempotent, func() error {
+			var err error
+			resp, err = c.rpc.ExecuteBatch(ctx, &pb.BatchRequest{Sqls: sqls})
+			return err
+		})
+		if err != nil {
+			return 0, newQueryError(ctx, fmt.Sprintf("批量执行 %d 条语句", len(sqls)), err)
+		}
+		if got := len(resp.GetResults()); got != len(sqls) {
+			return 0, fmt.Errorf("批量结果数量不匹配: 发送 %d 条，收到 %d 条", len(sqls), got)
+		}
+
+		rows := 0
+		results = make([]BatchResult, len(sqls))
+		for i, item := range resp.GetResults() {
+			if se := item.GetError(); se != nil {
+				results[i].Err = newQueryError(ctx, sqls[i], status.Error(codes.Code(se.GetCode()), se.GetMessage()))
+				continue
+			}
+			results[i].Response = newQueryResponse(ctx, item.GetResponse(), "")
+			rows += len(item.GetResponse().GetRows())
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
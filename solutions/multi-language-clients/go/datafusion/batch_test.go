@@ -0,0 +1,96 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+func TestExecuteBatchPartialFailure(t *testing.T) {
+	var got []string
+	srv := &fakeServer{
+		executeBatch: func(_ context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+			got = req.GetSqls()
+			ok := func(n int) *pb.BatchItem {
+				return &pb.BatchItem{Outcome: &pb.BatchItem_Response{Response: &pb.QueryResponse{Rows: make([]*pb.Row, n)}}}
+			}
+			return &pb.BatchResponse{Results: []*pb.BatchItem{
+				ok(1),
+				{Outcome: &pb.BatchItem_Error{Error: &pb.StatementError{
+					Code:    int32(codes.NotFound),
+					Message: "表 missing 不存在",
+				}}},
+				ok(3),
+			}}, nil
+		},
+	}
+	c := newTestClient(t, srv)
+
+	sqls := []string{"SELECT 1", "SELECT * FROM missing", "SELECT * FROM t"}
+	results, err := c.ExecuteBatch(context.Background(), sqls)
+	if err != nil {
+		t.Fatalf("单条语句失败不应使 ExecuteBatch 返回错误: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("服务端收到 %d 条语句, want 3 条且只发送一次", len(got))
+	}
+	if len(results) != 3 {
+		t.Fatalf("结果数量 = %d, want 3", len(results))
+	}
+
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil || results[i].Response == nil {
+			t.Errorf("第 %d 条语句: Err = %v, Response = %v, want 成功", i+1, results[i].Err, results[i].Response)
+		}
+	}
+	if n := len(results[0].Response.Rows); n != 1 {
+		t.Errorf("第 1 条语句行数 = %d, want 1", n)
+	}
+	if n := len(results[2].Response.Rows); n != 3 {
+		t.Errorf("第 3 条语句行数 = %d, want 3", n)
+	}
+
+	failed := results[1]
+	if failed.Response != nil {
+		t.Errorf("失败语句的 Response = %v, want nil", failed.Response)
+	}
+	var qe *QueryError
+	if !errors.As(failed.Err, &qe) {
+		t.Fatalf("失败语句的 Err = %v (%T), want *QueryError", failed.Err, failed.Err)
+	}
+	if qe.Code != codes.NotFound || qe.SQL != sqls[1] || qe.Message != "表 missing 不存在" {
+		t.Errorf("QueryError = {%s %q %q}, want {NotFound %q \"表 missing 不存在\"}", qe.Code, qe.SQL, qe.Message, sqls[1])
+	}
+}
+
+func TestExecuteBatchRequestFailure(t *testing.T) {
+	srv := &fakeServer{
+		executeBatch: func(context.Context, *pb.BatchRequest) (*pb.BatchResponse, error) {
+			return nil, status.Error(codes.PermissionDenied, "无权执行")
+		},
+	}
+	c := newTestClient(t, srv)
+
+	results, err := c.ExecuteBatch(context.Background(), []string{"SELECT 1", "SELECT 2"})
+	if status.Code(err) != codes.PermissionDenied || results != nil {
+		t.Errorf("ExecuteBatch = %v, %v, want nil, PermissionDenied", results, err)
+	}
+}
+
+func TestExecuteBatchResultCountMismatch(t *testing.T) {
+	srv := &fakeServer{
+		executeBatch: func(context.Context, *pb.BatchRequest) (*pb.BatchResponse, error) {
+			return &pb.BatchResponse{Results: []*pb.BatchItem{{}}}, nil
+		},
+	}
+	c := newTestClient(t, srv)
+
+	if _, err := c.ExecuteBatch(context.Background(), []string{"SELECT 1", "SELECT 2"}); err == nil {
+		t.Error("结果数量与语句数量不一致时应返回错误")
+	}
+}
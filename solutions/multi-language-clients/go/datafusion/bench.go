@@ -0,0 +1,115 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// BenchResult 是一次压测的统计结果。
+type BenchResult struct {
+	// Queries 是完成的查询数，包括失败的查询
+	Queries int64
+	Errors  int64
+	// Duration 是实际压测时长
+	Duration time.Duration
+	QPS      float64
+	// ErrorRate 是失败查询的比例 (0-1)
+	ErrorRate float64
+	// P50、P95、P99 是成功与失败查询合并计算的延迟分位数
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+func (r *BenchResult) String() string {
+	return fmt.Sprintf("%d 次查询，%.1f QPS，错误率 %.2f%%，p50 %s，p95 %s，p99 %s",
+		r.Queries, r.QPS, r.ErrorRate*100, r.P50, r.P95, r.P99)
+}
+
+// Benchmark 以 concurrency 个并发持续执行 sql，时长为 duration，返回吞吐与延迟统计。
+// 查询不经过结果缓存。ctx 提前结束时立即停止，返回已完成部分的统计和 ctx 的错误。
+func (c *DataFusionClient) Benchmark(ctx context.Context, sql string, concurrency int, duration time.Duration) (*BenchResult, error) {
+	return runBenchmark(ctx, concurrency, duration, func(ctx context.Context) error {
+		_, err := c.query(ctx, &pb.QueryRequest{Sql: sql})
+		return err
+	})
+}
+
+func runBenchmark(ctx context.Context, concurrency int, duration time.Duration, call func(context.Context) error) (*BenchResult, error) {
+	if concurrency <= 0 {
+		return nil, fmt.Errorf("无效的并发数: %d", concurrency)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("无效的压测时长: %s", duration)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		queries atomic.Int64
+		errs    atomic.Int64
+		wg      sync.WaitGroup
+	)
+	// 每个 worker 记录自己的延迟，结束后合并，避免加锁
+	latencies := make([][]time.Duration, concurrency)
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for runCtx.Err() == nil {
+				begin := time.Now()
+				err := call(runCtx)
+				if runCtx.Err() != nil {
+					// 压测结束时被中断的查询不计入
+					return
+				}
+				latencies[i] = append(latencies[i], time.Since(begin))
+				queries.Add(1)
+				if err != nil {
+					errs.Add(1)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var all []time.Duration
+	for _, l := range latencies {
+		all = append(all, l...)
+	}
+	res := &BenchResult{
+		Queries:  queries.Load(),
+		Errors:   errs.Load(),
+		Duration: time.Since(start),
+	}
+	if res.Queries > 0 {
+		res.QPS = float64(res.Queries) / res.Duration.Seconds()
+		res.ErrorRate = float64(res.Errors) / float64(res.Queries)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	res.P50 = percentile(all, 50)
+	res.P95 = percentile(all, 95)
+	res.P99 = percentile(all, 99)
+	return res, ctx.Err()
+}
+
+// percentile 按最近秩法计算已排序延迟的 p 分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
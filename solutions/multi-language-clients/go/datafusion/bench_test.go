@@ -0,0 +1,112 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(p%v) = %s, want %s", tt.p, got, tt.want)
+		}
+	}
+
+	small := []time.Duration{1, 2, 3}
+	if got := percentile(small, 50); got != 2 {
+		t.Errorf("3 个样本的 p50 = %d, want 2", got)
+	}
+	if got := percentile(small, 99); got != 3 {
+		t.Errorf("3 个样本的 p99 = %d, want 3", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("空样本的 p50 = %s, want 0", got)
+	}
+}
+
+func TestRunBenchmark(t *testing.T) {
+	var calls atomic.Int64
+	res, err := runBenchmark(context.Background(), 4, 100*time.Millisecond, func(ctx context.Context) error {
+		n := calls.Add(1)
+		time.Sleep(time.Millisecond)
+		if n%4 == 0 {
+			return errors.New("失败")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runBenchmark: %v", err)
+	}
+	if res.Queries == 0 || res.QPS <= 0 {
+		t.Fatalf("结果 = %+v", res)
+	}
+	if res.ErrorRate < 0.15 || res.ErrorRate > 0.35 {
+		t.Errorf("ErrorRate = %v, 约为 0.25", res.ErrorRate)
+	}
+	if !(res.P50 >= time.Millisecond && res.P50 <= res.P95 && res.P95 <= res.P99) {
+		t.Errorf("分位数 p50=%s p95=%s p99=%s", res.P50, res.P95, res.P99)
+	}
+}
+
+func TestRunBenchmarkStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	res, err := runBenchmark(ctx, 2, time.Minute, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("取消后 %s 才返回", elapsed)
+	}
+	if res.Queries != 0 {
+		t.Errorf("被中断的查询不应计入: %d", res.Queries)
+	}
+}
+
+func TestRunBenchmarkInvalidArgs(t *testing.T) {
+	call := func(context.Context) error { return nil }
+	if _, err := runBenchmark(context.Background(), 0, time.Second, call); err == nil {
+		t.Error("并发数为 0 时应返回错误")
+	}
+	if _, err := runBenchmark(context.Background(), 1, 0, call); err == nil {
+		t.Error("时长为 0 时应返回错误")
+	}
+}
+
+func TestBenchmarkAgainstServer(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithResultCache(10, time.Minute))
+
+	res, err := c.Benchmark(context.Background(), "SELECT 1", 2, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Benchmark: %v", err)
+	}
+	if res.Errors != 0 || res.Queries == 0 {
+		t.Fatalf("结果 = %+v", res)
+	}
+	if got := int64(srv.calls.Load()); got < res.Queries {
+		t.Errorf("服务端只收到 %d 次查询，压测完成了 %d 次，压测不应经过缓存", got, res.Queries)
+	}
+}
@@ -0,0 +1,144 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen 表示端点连续失败已被熔断，冷却结束前不再向其发送请求。
+var ErrCircuitOpen = errors.New("端点已熔断")
+
+// BreakerState 是熔断器的状态。
+type BreakerState int
+
+const (
+	// BreakerClosed 表示正常放行请求
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 表示熔断中，请求直接被拒绝
+	BreakerOpen
+	// BreakerHalfOpen 表示冷却结束，放行一个探测请求
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// WithCircuitBreaker 为 Pool 中的每个端点启用熔断：连续 failures 次失败后熔断，
+// 熔断期间该端点的请求立即返回 ErrCircuitOpen (或转移到其他端点)，
+// 经过 cooldown 后放行一个探测请求，成功则恢复，失败则继续熔断。
+// 只有端点故障 (如 Unavailable、Internal、服务端超时) 计为失败，SQL 错误不计入。
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(o *options) {
+		o.breakerFailures = failures
+		o.breakerCooldown = cooldown
+	}
+}
+
+// breaker 是单个端点的熔断器，未启用熔断时为 nil
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// probing 表示半开状态下的探测请求尚未结束
+	probing bool
+}
+
+func newBreaker(o *options) *breaker {
+	if o.breakerFailures <= 0 {
+		return nil
+	}
+	return &breaker{threshold: o.breakerFailures, cooldown: o.breakerCooldown, now: time.Now}
+}
+
+// allow 判断是否放行请求，半开状态下同时只放行一个探测请求
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record 记录一次放行请求的结果
+func (b *breaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if isClientAbort(ctx, err) {
+		// 调用方主动中止的请求不能说明端点是否正常
+		return
+	}
+	if !isEndpointFailure(ctx, err) {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// current 返回当前状态，冷却已结束的熔断器报告为半开
+func (b *breaker) current() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// isEndpointFailure 判断错误是否说明端点自身有问题，调用方上下文结束不计入
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.Unknown, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
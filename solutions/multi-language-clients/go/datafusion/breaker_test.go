@@ -0,0 +1,141 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestBreaker(failures int, cooldown time.Duration) (*breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := newBreaker(&options{breakerFailures: failures, breakerCooldown: cooldown})
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "连接断开")
+	b, clock := newTestBreaker(2, time.Second)
+
+	expect := func(want BreakerState) {
+		t.Helper()
+		if got := b.current(); got != want {
+			t.Fatalf("状态 = %v, want %v", got, want)
+		}
+	}
+
+	// closed: 未达到阈值前继续放行
+	expect(BreakerClosed)
+	if err := b.allow(); err != nil {
+		t.Fatalf("closed 状态应放行: %v", err)
+	}
+	b.record(ctx, unavailable)
+	expect(BreakerClosed)
+
+	// open: 连续失败达到阈值
+	b.allow()
+	b.record(ctx, unavailable)
+	expect(BreakerOpen)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open 状态 allow = %v, want ErrCircuitOpen", err)
+	}
+
+	// half-open: 冷却结束后只放行一个探测请求
+	clock.Advance(time.Second)
+	expect(BreakerHalfOpen)
+	if err := b.allow(); err != nil {
+		t.Fatalf("half-open 状态应放行探测请求: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("探测进行中 allow = %v, want ErrCircuitOpen", err)
+	}
+
+	// 探测失败重新熔断
+	b.record(ctx, unavailable)
+	expect(BreakerOpen)
+
+	// closed: 再次冷却后探测成功
+	clock.Advance(time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("half-open 状态应放行探测请求: %v", err)
+	}
+	b.record(ctx, nil)
+	expect(BreakerClosed)
+	if err := b.allow(); err != nil {
+		t.Fatalf("恢复后应放行: %v", err)
+	}
+}
+
+func TestBreakerIgnoresNonEndpointFailures(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Second)
+
+	b.record(context.Background(), status.Error(codes.InvalidArgument, "语法错误"))
+	if got := b.current(); got != BreakerClosed {
+		t.Errorf("SQL 错误后状态 = %v, want closed", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.record(ctx, status.Error(codes.Canceled, "取消"))
+	if got := b.current(); got != BreakerClosed {
+		t.Errorf("调用方取消后状态 = %v, want closed", got)
+	}
+}
+
+func TestBreakerClientAbortIsNeutral(t *testing.T) {
+	ctx := context.Background()
+	b, clock := newTestBreaker(1, time.Second)
+	b.allow()
+	b.record(ctx, status.Error(codes.Unavailable, "连接断开"))
+	clock.Advance(time.Second)
+
+	// 半开状态下的探测被调用方提前关闭，既不恢复也不重新熔断
+	if err := b.allow(); err != nil {
+		t.Fatalf("half-open 状态应放行探测请求: %v", err)
+	}
+	b.record(ctx, ErrStreamClosed)
+	if got := b.current(); got != BreakerHalfOpen {
+		t.Fatalf("探测被中止后状态 = %v, want half-open", got)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("探测被中止后应放行新的探测: %v", err)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(&options{})
+	if b != nil {
+		t.Fatal("未配置时不应创建熔断器")
+	}
+	b.record(context.Background(), status.Error(codes.Unavailable, ""))
+	if err := b.allow(); err != nil || b.current() != BreakerClosed {
+		t.Errorf("nil 熔断器应始终放行: %v", err)
+	}
+}
+
+func TestPoolCircuitBreaker(t *testing.T) {
+	addr := startServer(t, (&statusServer{code: codes.Unavailable}).fake())
+	p, err := NewPool([]string{addr}, WithInsecure(), WithCircuitBreaker(2, time.Hour))
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := p.ExecuteQuery(ctx, "SELECT 1"); status.Code(err) != codes.Unavailable {
+			t.Fatalf("第 %d 次查询 err = %v, want Unavailable", i+1, err)
+		}
+	}
+	if _, err := p.ExecuteQuery(ctx, "SELECT 1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("熔断后 err = %v, want ErrCircuitOpen", err)
+	}
+	if stats := p.Stats(); stats[0].Breaker != BreakerOpen || stats[0].Healthy {
+		t.Errorf("Stats = %+v", stats[0])
+	}
+}
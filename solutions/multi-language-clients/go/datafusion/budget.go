@@ -0,0 +1,83 @@
+package datafusion
+
+import (
+	"context"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// BudgetPolicy 决定 ExecuteBatch 如何在语句之间分配截止时间前的剩余时间。
+type BudgetPolicy int
+
+const (
+	// budgetNone 是默认行为：整个批次在一次 RPC 中执行
+	budgetNone BudgetPolicy = iota
+	// BudgetEqualSplit 让每条语句最多使用剩余时间除以剩余语句数，
+	// 避免前面的慢查询耗尽整个批次的时间
+	BudgetEqualSplit
+	// BudgetRemaining 让每条语句使用截止时间前剩余的全部时间
+	BudgetRemaining
+)
+
+func (p BudgetPolicy) String() string {
+	switch p {
+	case BudgetEqualSplit:
+		return "equal-split"
+	case BudgetRemaining:
+		return "remaining"
+	default:
+		return "none"
+	}
+}
+
+// WithBudgetPolicy 让 ExecuteBatch 逐条执行语句，并按 policy 为每条语句单独设置截止时间。
+// 每条语句结束后根据剩余时间和剩余语句数重新计算。
+// 上下文没有截止时间且未设置 WithQueryTimeout 时语句不受时间限制。
+func WithBudgetPolicy(policy BudgetPolicy) Option {
+	return func(o *options) {
+		o.budgetPolicy = policy
+	}
+}
+
+// executeBudgeted 逐条执行 sqls，按预算策略为每条语句派生截止时间。
+// WithQueryTimeout 作用于整个批次，语句的截止时间只从批次的截止时间派生
+func (c *DataFusionClient) executeBudgeted(ctx context.Context, sqls []string) ([]BatchResult, error) {
+	// 客户端已关闭或 ctx 已结束时整个批次失败，而不是每条语句各自失败
+	if c.calls.closed() {
+		return nil, ErrClientClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	results := make([]BatchResult, len(sqls))
+	for i, sql := range sqls {
+		stmtCtx, stmtCancel := statementBudget(ctx, c.opts.budgetPolicy, len(sqls)-i)
+		resp, err := c.runQuery(stmtCtx, &pb.QueryRequest{Sql: sql}, 0)
+		stmtCancel()
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Response = resp
+	}
+	return results, nil
+}
+
+// statementBudget 为剩余 left 条语句中的下一条派生截止时间
+func statementBudget(ctx context.Context, policy BudgetPolicy, left int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || policy != BudgetEqualSplit || left <= 1 {
+		return context.WithCancel(ctx)
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, remaining/time.Duration(left))
+}
@@ -0,0 +1,216 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// budgetServer 记录每条语句收到时距截止时间的剩余时间，
+// 以 "SLEEP <duration>" 开头的语句先等待再返回
+type budgetServer struct {
+	mu sync.Mutex
+	// budgets 为 -1 表示语句没有截止时间
+	budgets []time.Duration
+}
+
+func (s *budgetServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *budgetServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	budget := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		budget = time.Until(deadline)
+	}
+	s.mu.Lock()
+	s.budgets = append(s.budgets, budget)
+	s.mu.Unlock()
+
+	if rest, ok := strings.CutPrefix(req.GetSql(), "SLEEP "); ok {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return &pb.QueryResponse{}, nil
+}
+
+func (s *budgetServer) recorded() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.budgets...)
+}
+
+// budgetTolerance 容许网络往返和调度造成的误差
+const budgetTolerance = 40 * time.Millisecond
+
+func TestBudgetPolicySubDeadlines(t *testing.T) {
+	const deadline = 600 * time.Millisecond
+	// 第一条语句耗时 100ms，之后剩余约 500ms
+	sqls := []string{"SLEEP 100ms", "SELECT 2", "SELECT 3"}
+	tests := []struct {
+		policy BudgetPolicy
+		want   []time.Duration
+	}{
+		// 600/3，500/2，最后一条使用全部剩余时间
+		{BudgetEqualSplit, []time.Duration{200 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond}},
+		{BudgetRemaining, []time.Duration{600 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			srv := &budgetServer{}
+			c := newTestClient(t, srv.fake(), WithBudgetPolicy(tt.policy))
+
+			ctx, cancel := context.WithTimeout(context.Background(), deadline)
+			defer cancel()
+			results, err := c.ExecuteBatch(ctx, sqls)
+			if err != nil {
+				t.Fatalf("ExecuteBatch: %v", err)
+			}
+			for i, r := range results {
+				if r.Err != nil {
+					t.Errorf("语句 %d: %v", i+1, r.Err)
+				}
+			}
+
+			got := srv.recorded()
+			if len(got) != len(tt.want) {
+				t.Fatalf("服务端收到 %d 条语句, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i] > want || got[i] < want-budgetTolerance {
+					t.Errorf("语句 %d 的剩余时间 = %v, want 约 %v", i+1, got[i].Round(time.Millisecond), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBudgetEqualSplitProtectsLaterStatements(t *testing.T) {
+	// 第一条语句需要 300ms，超过均分得到的 200ms
+	sqls := []string{"SLEEP 300ms", "SELECT 2", "SELECT 3"}
+
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetEqualSplit))
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	results, err := c.ExecuteBatch(ctx, sqls)
+	if err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	if code := status.Code(results[0].Err); code != codes.DeadlineExceeded {
+		t.Errorf("语句 1 err = %v, want DeadlineExceeded", results[0].Err)
+	}
+	for i, r := range results[1:] {
+		if r.Err != nil {
+			t.Errorf("语句 %d: %v, want 仍在剩余时间内完成", i+2, r.Err)
+		}
+	}
+}
+
+func TestBudgetRemainingLetsSlowStatementFinish(t *testing.T) {
+	sqls := []string{"SLEEP 300ms", "SELECT 2", "SELECT 3"}
+
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetRemaining))
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	results, err := c.ExecuteBatch(ctx, sqls)
+	if err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Errorf("语句 %d: %v", i+1, r.Err)
+		}
+	}
+}
+
+func TestBudgetWithoutDeadline(t *testing.T) {
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetEqualSplit))
+	if _, err := c.ExecuteBatch(context.Background(), []string{"SELECT 1", "SELECT 2"}); err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	for i, b := range srv.recorded() {
+		if b != -1 {
+			t.Errorf("语句 %d 的剩余时间 = %v, want 没有截止时间", i+1, b)
+		}
+	}
+}
+
+func TestBudgetWithQueryTimeout(t *testing.T) {
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetEqualSplit), WithQueryTimeout(400*time.Millisecond))
+	if _, err := c.ExecuteBatch(context.Background(), []string{"SELECT 1", "SELECT 2"}); err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	// WithQueryTimeout 是整个批次的预算，而不是每条语句重新计时
+	got := srv.recorded()
+	if len(got) != 2 || got[0] > 200*time.Millisecond || got[0] < 200*time.Millisecond-budgetTolerance {
+		t.Errorf("剩余时间 = %v, want 首条约 200ms", got)
+	}
+	if got[1] > 400*time.Millisecond {
+		t.Errorf("语句 2 的剩余时间 = %v, want 不超过批次的 400ms", got[1])
+	}
+}
+
+func TestBudgetRemainingSharesQueryTimeout(t *testing.T) {
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetRemaining), WithQueryTimeout(400*time.Millisecond))
+	if _, err := c.ExecuteBatch(context.Background(), []string{"SLEEP 150ms", "SELECT 2"}); err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	// 第二条语句只能使用批次剩下的约 250ms
+	got := srv.recorded()
+	if len(got) != 2 || got[1] > 250*time.Millisecond || got[1] < 250*time.Millisecond-budgetTolerance {
+		t.Errorf("剩余时间 = %v, want 第二条约 250ms", got)
+	}
+}
+
+func TestBudgetFailsFastBeforeFirstStatement(t *testing.T) {
+	srv := &budgetServer{}
+	c := newTestClient(t, srv.fake(), WithBudgetPolicy(BudgetEqualSplit))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, err := c.ExecuteBatch(ctx, []string{"SELECT 1", "SELECT 2"}); !errors.Is(err, context.Canceled) || results != nil {
+		t.Errorf("ctx 已取消时 ExecuteBatch = %v, %v, want nil, context.Canceled", results, err)
+	}
+
+	c.Close()
+	if results, err := c.ExecuteBatch(context.Background(), []string{"SELECT 1", "SELECT 2"}); !errors.Is(err, ErrClientClosed) || results != nil {
+		t.Errorf("关闭后 ExecuteBatch = %v, %v, want nil, ErrClientClosed", results, err)
+	}
+	if got := srv.recorded(); len(got) != 0 {
+		t.Errorf("不应向服务端发送语句，服务端收到 %d 条", len(got))
+	}
+}
+
+func TestBudgetPolicyString(t *testing.T) {
+	for policy, want := range map[BudgetPolicy]string{
+		budgetNone:       "none",
+		BudgetEqualSplit: "equal-split",
+		BudgetRemaining:  "remaining",
+	} {
+		if got := policy.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(policy), got, want)
+		}
+	}
+}
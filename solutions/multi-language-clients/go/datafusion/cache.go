@@ -0,0 +1,179 @@
+package datafusion
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// WithResultCache 缓存 SELECT 查询的结果，最多保留 size 条，每条在 ttl 后过期。
+// 缓存以规范化后的 SQL 为键，命中时不访问服务端。
+// 命中时返回结果的副本，RequestID 为本次调用的请求 ID；
+// Columns 和 Rows 与缓存共享，调用方不应修改。
+func WithResultCache(size int, ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheSize = size
+		o.cacheTTL = ttl
+	}
+}
+
+// CacheStats 是结果缓存的统计。
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Size 是当前缓存的条目数
+	Size int
+}
+
+// InvalidateCache 清空结果缓存。
+func (c *DataFusionClient) InvalidateCache() {
+	c.cache.clear()
+}
+
+// CacheStats 返回结果缓存的命中统计，未启用缓存时为零值。
+func (c *DataFusionClient) CacheStats() CacheStats {
+	return c.cache.stats()
+}
+
+// resultCache 是带过期时间的 LRU 缓存，nil 表示未启用
+type resultCache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu     sync.Mutex
+	lru    *list.List
+	items  map[string]*list.Element
+	hits   uint64
+	misses uint64
+}
+
+type cacheEntry struct {
+	key     string
+	resp    *QueryResponse
+	expires time.Time
+}
+
+func newResultCache(size int, ttl time.Duration) *resultCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &resultCache{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// cacheKey 规范化 SQL：合并引号外的空白并去掉结尾的分号，引号内的字面量保持原样
+func cacheKey(sql string) string {
+	var b strings.Builder
+	var quote rune
+	space := false
+	for _, r := range strings.TrimSpace(sql) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '\'' || r == '"':
+			quote = r
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if quote != 0 {
+		return b.String()
+	}
+	return strings.TrimRight(b.String(), "; ")
+}
+
+// isCacheable 判断查询结果是否可以缓存，只缓存 SELECT (含 WITH ... SELECT)，
+// 多条语句时每条都必须可缓存
+func isCacheable(sql string) bool {
+	if classifyStatement(sql) != StatementRead {
+		return false
+	}
+	for _, kw := range leadingKeywords(sql) {
+		switch kw {
+		case "SELECT", "WITH", "VALUES":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (rc *resultCache) get(sql string) (*QueryResponse, bool) {
+	if rc == nil || !isCacheable(sql) {
+		return nil, false
+	}
+	key := cacheKey(sql)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	el, ok := rc.items[key]
+	if ok {
+		entry := el.Value.(*cacheEntry)
+		if rc.now().Before(entry.expires) {
+			rc.lru.MoveToFront(el)
+			rc.hits++
+			return entry.resp, true
+		}
+		rc.lru.Remove(el)
+		delete(rc.items, key)
+	}
+	rc.misses++
+	return nil, false
+}
+
+func (rc *resultCache) put(sql string, resp *QueryResponse) {
+	if rc == nil || !isCacheable(sql) {
+		return
+	}
+	key := cacheKey(sql)
+	entry := &cacheEntry{key: key, resp: resp, expires: rc.now().Add(rc.ttl)}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if el, ok := rc.items[key]; ok {
+		el.Value = entry
+		rc.lru.MoveToFront(el)
+		return
+	}
+	rc.items[key] = rc.lru.PushFront(entry)
+	for rc.lru.Len() > rc.size {
+		oldest := rc.lru.Back()
+		rc.lru.Remove(oldest)
+		delete(rc.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (rc *resultCache) clear() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.lru.Init()
+	rc.items = make(map[string]*list.Element)
+}
+
+func (rc *resultCache) stats() CacheStats {
+	if rc == nil {
+		return CacheStats{}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return CacheStats{Hits: rc.hits, Misses: rc.misses, Size: rc.lru.Len()}
+}
@@ -0,0 +1,156 @@
+package datafusion
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// countingServer 记录收到的查询次数
+type countingServer struct {
+	calls atomic.Int32
+}
+
+func (s *countingServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *countingServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	s.calls.Add(1)
+	return &pb.QueryResponse{Result: req.GetSql()}, nil
+}
+
+// fakeClock 是可以手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithResultCache(10, time.Minute))
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	c.cache.now = clock.Now
+
+	ctx := context.Background()
+	first, err := c.ExecuteQuery(ctx, "SELECT * FROM t")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	second, err := c.ExecuteQuery(ctx, "  SELECT *\n FROM t;")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Fatalf("TTL 内的相同查询访问了服务端 %d 次, want 1", got)
+	}
+	if second.Result != first.Result {
+		t.Errorf("缓存结果 = %q, want %q", second.Result, first.Result)
+	}
+	if second.RequestID == "" || second.RequestID == first.RequestID {
+		t.Errorf("缓存命中的 RequestID = %q, 首次调用为 %q", second.RequestID, first.RequestID)
+	}
+	if got := c.CacheStats(); got.Hits != 1 || got.Misses != 1 || got.Size != 1 {
+		t.Errorf("CacheStats = %+v", got)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := c.ExecuteQuery(ctx, "SELECT * FROM t"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("过期后的查询访问了服务端 %d 次, want 2", got)
+	}
+}
+
+func TestResultCacheUsesCallerRequestID(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithResultCache(10, time.Minute))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ExecuteQuery(WithRequestID(context.Background(), "req-2"), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.RequestID != "req-2" {
+		t.Errorf("RequestID = %q, want req-2", resp.RequestID)
+	}
+}
+
+func TestResultCacheSkipsWritesAndOptions(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithResultCache(10, time.Minute))
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.ExecuteQuery(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.ExecuteQuery(ctx, "SELECT 1", WithQueryMetadata(map[string]string{"x-k": "v"})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := srv.calls.Load(); got != 4 {
+		t.Errorf("服务端调用次数 = %d, want 4", got)
+	}
+}
+
+func TestResultCacheEvictsLRU(t *testing.T) {
+	rc := newResultCache(2, time.Minute)
+	rc.put("SELECT 1", &QueryResponse{Result: "1"})
+	rc.put("SELECT 2", &QueryResponse{Result: "2"})
+	rc.get("SELECT 1")
+	rc.put("SELECT 3", &QueryResponse{Result: "3"})
+
+	if _, ok := rc.get("SELECT 2"); ok {
+		t.Error("最久未使用的条目应被淘汰")
+	}
+	for _, sql := range []string{"SELECT 1", "SELECT 3"} {
+		if _, ok := rc.get(sql); !ok {
+			t.Errorf("%s 不应被淘汰", sql)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"SELECT 1", "  SELECT\t1 ;", true},
+		{"SELECT *\nFROM t", "SELECT * FROM t", true},
+		{"SELECT 'a  b'", "SELECT 'a b'", false},
+		{`SELECT "my  col" FROM t`, `SELECT "my col" FROM t`, false},
+		{"SELECT 'it''s  here'", "SELECT 'it''s here'", false},
+		{"SELECT ';'", "SELECT ''", false},
+	}
+	for _, tt := range tests {
+		if got := cacheKey(tt.a) == cacheKey(tt.b); got != tt.same {
+			t.Errorf("cacheKey(%q) == cacheKey(%q) 为 %v, want %v (%q, %q)",
+				tt.a, tt.b, got, tt.same, cacheKey(tt.a), cacheKey(tt.b))
+		}
+	}
+	if got := cacheKey("SELECT 'a  b' ;"); got != "SELECT 'a  b'" {
+		t.Errorf("cacheKey = %q", got)
+	}
+}
@@ -0,0 +1,43 @@
+package datafusion
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// 服务端返回查询 ID 的响应头
+const queryIDHeader = "x-query-id"
+
+// 发送 CancelQuery 的超时
+const cancelTimeout = 5 * time.Second
+
+// CancelQuery 请求服务端终止 queryID 对应的查询。
+func (c *DataFusionClient) CancelQuery(ctx context.Context, queryID string) error {
+	_, err := c.rpc.CancelQuery(ctx, &pb.CancelQueryRequest{QueryId: queryID})
+	return err
+}
+
+// queryIDFromHeader 从响应头中取出查询 ID
+func queryIDFromHeader(md metadata.MD) string {
+	if ids := md.Get(queryIDHeader); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// cancelOnServer 在后台通知服务端终止查询。
+// 调用方的上下文已结束，因此使用独立的上下文发送。
+func (c *DataFusionClient) cancelOnServer(queryID string) {
+	if queryID == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancel()
+		_ = c.CancelQuery(ctx, queryID)
+	}()
+}
@@ -0,0 +1,157 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/stats"
+)
+
+// activeQueries 是进行中查询的登记表，供 CancelAll 使用
+type activeQueries struct {
+	mu      sync.Mutex
+	queries map[*activeQuery]struct{}
+}
+
+type activeQuery struct {
+	owner  *activeQueries
+	cancel context.CancelFunc
+	// queryID 在收到服务端响应头后设置，notified 表示已通知服务端终止查询，
+	// 均由 owner.mu 保护
+	queryID  string
+	notified bool
+}
+
+// add 登记一个查询，返回的 remove 在查询结束时调用
+func (a *activeQueries) add(cancel context.CancelFunc) (q *activeQuery, remove func()) {
+	q = &activeQuery{owner: a, cancel: cancel}
+	a.mu.Lock()
+	if a.queries == nil {
+		a.queries = make(map[*activeQuery]struct{})
+	}
+	a.queries[q] = struct{}{}
+	a.mu.Unlock()
+	return q, func() {
+		a.mu.Lock()
+		delete(a.queries, q)
+		a.mu.Unlock()
+	}
+}
+
+func (q *activeQuery) setQueryID(id string) {
+	q.owner.mu.Lock()
+	q.queryID = id
+	q.owner.mu.Unlock()
+}
+
+// claimCancel 返回需要通知服务端终止的查询 ID，保证每个查询最多通知一次。
+// 还不知道 q 的查询 ID 时使用 fallback；q 为 nil 表示查询未登记
+func (q *activeQuery) claimCancel(fallback string) (string, bool) {
+	if q == nil {
+		return fallback, fallback != ""
+	}
+	q.owner.mu.Lock()
+	defer q.owner.mu.Unlock()
+	id := q.queryID
+	if id == "" {
+		id = fallback
+	}
+	if q.notified || id == "" {
+		return "", false
+	}
+	q.notified = true
+	return id, true
+}
+
+// snapshot 返回当前登记的查询
+func (a *activeQueries) snapshot() []*activeQuery {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]*activeQuery, 0, len(a.queries))
+	for q := range a.queries {
+		out = append(out, q)
+	}
+	return out
+}
+
+type activeQueryKey struct{}
+
+// track 将一次调用登记为进行中的查询，使 CancelAll 可以终止它。
+// RPC 应使用返回的 ctx，调用结束后执行 untrack
+func (c *DataFusionClient) track(ctx context.Context) (_ context.Context, untrack func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	active, remove := c.active.add(cancel)
+	return withActiveQuery(ctx, active), func() {
+		remove()
+		cancel()
+	}
+}
+
+// withActiveQuery 让 queryIDHandler 在响应头到达时把查询 ID 记到 q 上
+func withActiveQuery(ctx context.Context, q *activeQuery) context.Context {
+	return context.WithValue(ctx, activeQueryKey{}, q)
+}
+
+func activeQueryFrom(ctx context.Context) *activeQuery {
+	q, _ := ctx.Value(activeQueryKey{}).(*activeQuery)
+	return q
+}
+
+// queryIDHandler 是在响应头到达时登记查询 ID 的 gRPC stats.Handler。
+// grpc.Header 要到调用结束才填充，CancelAll 需要在非流式查询返回前就拿到 ID
+type queryIDHandler struct{}
+
+func (queryIDHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (queryIDHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	in, ok := s.(*stats.InHeader)
+	if !ok || !in.IsClient() {
+		return
+	}
+	if q := activeQueryFrom(ctx); q != nil {
+		if id := queryIDFromHeader(in.Header); id != "" {
+			q.setQueryID(id)
+		}
+	}
+}
+
+func (queryIDHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (queryIDHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// CancelAll 终止客户端上所有进行中的查询 (包括未读完的结果流)，
+// 被终止的调用返回 Canceled。已收到查询 ID 的查询逐个发送 CancelQuery，
+// 返回用 errors.Join 汇总的发送失败；还没有收到查询 ID 的查询只在本地取消。
+func (c *DataFusionClient) CancelAll(ctx context.Context) error {
+	queries := c.active.snapshot()
+	errs := make([]error, 0, len(queries))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, q := range queries {
+		// 先认领再取消，避免调用方同时在后台重复通知服务端
+		id, ok := q.claimCancel("")
+		q.cancel()
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if err := c.CancelQuery(ctx, id); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("取消查询 %s 失败: %w", id, err))
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
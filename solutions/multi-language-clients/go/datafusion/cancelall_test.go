@@ -0,0 +1,218 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// waitQueryIDs 等待 n 个进行中的查询都收到查询 ID
+func waitQueryIDs(t *testing.T, c *DataFusionClient, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		known := 0
+		queries := c.active.snapshot()
+		c.active.mu.Lock()
+		for _, q := range queries {
+			if q.queryID != "" {
+				known++
+			}
+		}
+		c.active.mu.Unlock()
+		if known == n && len(queries) == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("等待 %d 个查询收到查询 ID 超时", n)
+}
+
+// startSlowQueries 并发发起 n 个阻塞的查询，返回接收各自错误的通道
+func startSlowQueries(t *testing.T, c *DataFusionClient, srv *cancelServer, n int) <-chan error {
+	t.Helper()
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := c.ExecuteQuery(context.Background(), "SELECT slow")
+			results <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		<-srv.started
+	}
+	waitQueryIDs(t, c, n)
+	return results
+}
+
+func TestCancelAllCancelsConcurrentQueries(t *testing.T) {
+	srv := newCancelServer()
+	c := newTestClient(t, srv.fake())
+	results := startSlowQueries(t, c, srv, 3)
+
+	if err := c.CancelAll(context.Background()); err != nil {
+		t.Fatalf("CancelAll: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-results:
+			if status.Code(err) != codes.Canceled {
+				t.Errorf("查询 err = %v, want Canceled", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("CancelAll 后查询未返回")
+		}
+	}
+
+	got := srv.waitCanceled(t, 3)
+	// 留出时间让重复的后台通知 (如果有) 到达
+	time.Sleep(50 * time.Millisecond)
+	srv.mu.Lock()
+	got = slices.Clone(srv.canceled)
+	srv.mu.Unlock()
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"q-1", "q-2", "q-3"}) {
+		t.Errorf("服务端收到的 CancelQuery = %v, want 每个查询各一次", got)
+	}
+	if n := len(c.active.snapshot()); n != 0 {
+		t.Errorf("结束后仍有 %d 个登记的查询", n)
+	}
+}
+
+func TestCancelAllJoinsServerErrors(t *testing.T) {
+	srv := newCancelServer()
+	srv.cancelErr = status.Error(codes.Internal, "取消失败")
+	c := newTestClient(t, srv.fake())
+	results := startSlowQueries(t, c, srv, 2)
+
+	err := c.CancelAll(context.Background())
+	if err == nil {
+		t.Fatal("CancelQuery 失败时 CancelAll 应返回错误")
+	}
+	for _, id := range []string{"q-1", "q-2"} {
+		if !strings.Contains(err.Error(), id) {
+			t.Errorf("错误 %q 未提及 %s", err, id)
+		}
+	}
+	if u, ok := err.(interface{ Unwrap() []error }); !ok || len(u.Unwrap()) != 2 {
+		t.Errorf("err = %#v, want 2 个汇总的错误", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-results; status.Code(err) != codes.Canceled {
+			t.Errorf("查询 err = %v, want Canceled", err)
+		}
+	}
+}
+
+func TestCancelAllCancelsOtherEntryPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(ctx context.Context, c *DataFusionClient) error
+	}{
+		{"Arrow", func(ctx context.Context, c *DataFusionClient) error {
+			_, err := c.ExecuteQueryArrow(ctx, "SELECT slow")
+			return err
+		}},
+		{"Prepared", func(ctx context.Context, c *DataFusionClient) error {
+			stmt, err := c.Prepare(ctx, "SELECT slow WHERE id = $1")
+			if err != nil {
+				return err
+			}
+			_, err = stmt.Query(ctx, 1)
+			return err
+		}},
+		{"Batch", func(ctx context.Context, c *DataFusionClient) error {
+			_, err := c.ExecuteBatch(ctx, []string{"SELECT 1", "SELECT 2"})
+			return err
+		}},
+		{"Cursor", func(ctx context.Context, c *DataFusionClient) error {
+			_, err := c.QueryPaged(ctx, "SELECT slow", 10)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCancelServer()
+			c := newTestClient(t, srv.fake())
+			result := make(chan error, 1)
+			go func() { result <- tt.call(context.Background(), c) }()
+			<-srv.started
+			waitQueryIDs(t, c, 1)
+
+			if err := c.CancelAll(context.Background()); err != nil {
+				t.Fatalf("CancelAll: %v", err)
+			}
+			select {
+			case err := <-result:
+				if status.Code(err) != codes.Canceled {
+					t.Errorf("err = %v, want Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("CancelAll 后调用未返回")
+			}
+			if got := srv.waitCanceled(t, 1); got[0] != "q-1" {
+				t.Errorf("服务端收到的 CancelQuery = %v, want [q-1]", got)
+			}
+		})
+	}
+}
+
+func TestCancelAllCancelsStreams(t *testing.T) {
+	srv := newCancelServer()
+	c := newTestClient(t, srv.fake())
+
+	s, err := c.ExecuteQueryStream(context.Background(), "SELECT x FROM t")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	waitQueryIDs(t, c, 1)
+
+	if err := c.CancelAll(context.Background()); err != nil {
+		t.Fatalf("CancelAll: %v", err)
+	}
+	if _, err := s.Next(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Next err = %v, want context.Canceled", err)
+	}
+	if got := srv.waitCanceled(t, 1); got[0] != "q-1" {
+		t.Errorf("服务端收到的 CancelQuery = %v", got)
+	}
+}
+
+func TestCancelAllWithoutQueries(t *testing.T) {
+	c := newTestClient(t, newCancelServer().fake())
+	if err := c.CancelAll(context.Background()); err != nil {
+		t.Errorf("CancelAll: %v", err)
+	}
+}
+
+func TestQueryContextCancelNotifiesServerOnce(t *testing.T) {
+	srv := newCancelServer()
+	c := newTestClient(t, srv.fake())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		_, err := c.ExecuteQuery(ctx, "SELECT slow")
+		result <- err
+	}()
+	<-srv.started
+	waitQueryIDs(t, c, 1)
+	cancel()
+
+	if err := <-result; status.Code(err) != codes.Canceled {
+		t.Errorf("err = %v, want Canceled", err)
+	}
+	if got := srv.waitCanceled(t, 1); got[0] != "q-1" {
+		t.Errorf("服务端收到的 CancelQuery = %v", got)
+	}
+}
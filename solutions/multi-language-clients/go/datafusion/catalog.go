@@ -0,0 +1,128 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"datafusion-client/pb"
+)
+
+// TableInfo 描述目录中的一张表。
+type TableInfo struct {
+	Catalog string
+	Schema  string
+	Name    string
+	// Type 是表类型，如 BASE TABLE、VIEW
+	Type string
+}
+
+// QualifiedName 返回 schema.table 形式的表名，schema 为空时只返回表名。
+func (t TableInfo) QualifiedName() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// Schema 是一张表的结构。
+type Schema struct {
+	Table   TableInfo
+	Columns []Column
+}
+
+// ListTables 列出服务端目录中的所有表。
+func (c *DataFusionClient) ListTables(ctx context.Context) ([]TableInfo, error) {
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	resp, err := c.rpc.ListTables(ctx, &pb.ListTablesRequest{})
+	if err != nil {
+		return nil, newQueryError(ctx, "", err)
+	}
+	tables := make([]TableInfo, len(resp.GetTables()))
+	for i, t := range resp.GetTables() {
+		tables[i] = tableInfoFromPB(t)
+	}
+	return tables, nil
+}
+
+// DescribeTable 返回表结构。name 可以带 schema 或 catalog 限定，
+// 如 users、public.users、datafusion.public.users，双引号内的点不作为分隔符。
+func (c *DataFusionClient) DescribeTable(ctx context.Context, name string) (*Schema, error) {
+	parts, err := splitQualifiedName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	req := &pb.DescribeTableRequest{Table: parts[len(parts)-1]}
+	if len(parts) >= 2 {
+		req.Schema = parts[len(parts)-2]
+	}
+	if len(parts) == 3 {
+		req.Catalog = parts[0]
+	}
+
+	ctx = ensureRequestID(ctx)
+	resp, err := c.rpc.DescribeTable(ctx, req)
+	if err != nil {
+		return nil, newQueryError(ctx, "", err)
+	}
+	return &Schema{
+		Table:   tableInfoFromPB(resp.GetTable()),
+		Columns: columnsFromPB(resp.GetColumns()),
+	}, nil
+}
+
+func tableInfoFromPB(t *pb.TableInfo) TableInfo {
+	return TableInfo{
+		Catalog: t.GetCatalog(),
+		Schema:  t.GetSchema(),
+		Name:    t.GetName(),
+		Type:    t.GetTableType(),
+	}
+}
+
+// splitQualifiedName 将限定表名拆分为至多三段，去掉标识符两侧的双引号
+func splitQualifiedName(name string) ([]string, error) {
+	var (
+		parts  []string
+		cur    strings.Builder
+		quoted bool
+	)
+	for _, r := range name {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("无效的表名 %q: 引号未闭合", name)
+	}
+	parts = append(parts, cur.String())
+
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("无效的表名 %q: 最多支持 catalog.schema.table 三段", name)
+	}
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("无效的表名 %q", name)
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,162 @@
+package datafusion
+
+import (
+	"strings"
+	"unicode"
+)
+
+// StatementKind 是 SQL 语句的读写类别。
+type StatementKind int
+
+const (
+	// StatementUnknown 表示无法识别的语句，按写语句处理
+	StatementUnknown StatementKind = iota
+	// StatementRead 表示只读语句，如 SELECT、EXPLAIN
+	StatementRead
+	// StatementWrite 表示会修改数据或结构的语句，如 INSERT、CREATE
+	StatementWrite
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case StatementRead:
+		return "read"
+	case StatementWrite:
+		return "write"
+	default:
+		return "unknown"
+	}
+}
+
+var readKeywords = map[string]bool{
+	"SELECT":   true,
+	"EXPLAIN":  true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"VALUES":   true,
+}
+
+var writeKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"CREATE":   true,
+	"DROP":     true,
+	"ALTER":    true,
+	"TRUNCATE": true,
+	"COPY":     true,
+}
+
+// classifyStatement 判断语句是读还是写，忽略开头的空白和注释。
+// WITH 开头的语句按 CTE 之后的主语句分类，如 WITH ... INSERT 属于写语句；
+// EXPLAIN ANALYZE 同样按其后的语句分类。
+// 以顶层分号分隔的多条语句按其中最强的类别分类：任一条是写语句即为写，
+// 否则任一条无法识别即为未知，全部只读时才是读。
+func classifyStatement(sql string) StatementKind {
+	stmts := statementWords(sql)
+	if len(stmts) == 0 {
+		return StatementUnknown
+	}
+	kind := StatementRead
+	for _, words := range stmts {
+		switch classifyWords(words) {
+		case StatementWrite:
+			return StatementWrite
+		case StatementUnknown:
+			kind = StatementUnknown
+		}
+	}
+	return kind
+}
+
+// classifyWords 按单条语句的顶层单词分类
+func classifyWords(words []string) StatementKind {
+	first := words[0]
+	// EXPLAIN ANALYZE 会真正执行语句，按被分析的语句分类
+	analyze := first == "EXPLAIN" && len(words) > 1 && words[1] == "ANALYZE"
+	if first == "WITH" || analyze {
+		for _, w := range words[1:] {
+			if readKeywords[w] {
+				return StatementRead
+			}
+			if writeKeywords[w] {
+				return StatementWrite
+			}
+		}
+		return StatementUnknown
+	}
+	if readKeywords[first] {
+		return StatementRead
+	}
+	if writeKeywords[first] {
+		return StatementWrite
+	}
+	return StatementUnknown
+}
+
+// leadingKeywords 返回每条语句的第一个关键字 (大写)
+func leadingKeywords(sql string) []string {
+	stmts := statementWords(sql)
+	out := make([]string, len(stmts))
+	for i, words := range stmts {
+		out[i] = words[0]
+	}
+	return out
+}
+
+// statementWords 按顶层分号拆分语句，返回每条非空语句中
+// 不在括号、字符串、引号标识符和注释内的单词 (大写)
+func statementWords(sql string) [][]string {
+	var (
+		stmts [][]string
+		words []string
+		depth int
+	)
+	rs := []rune(sql)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			i += 2
+			for i+1 < len(rs) && !(rs[i] == '*' && rs[i+1] == '/') {
+				i++
+			}
+			i++
+		case r == '\'' || r == '"':
+			i++
+			for i < len(rs) && rs[i] != r {
+				i++
+			}
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case r == ';' && depth == 0:
+			if len(words) > 0 {
+				stmts = append(stmts, words)
+			}
+			words = nil
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < len(rs) && (unicode.IsLetter(rs[i+1]) || unicode.IsDigit(rs[i+1]) || rs[i+1] == '_') {
+				i++
+			}
+			// 语句整体被括号包裹时，第一个单词可能位于括号内
+			if depth == 0 || len(words) == 0 {
+				words = append(words, strings.ToUpper(string(rs[start:i+1])))
+			}
+		}
+	}
+	if len(words) > 0 {
+		stmts = append(stmts, words)
+	}
+	return stmts
+}
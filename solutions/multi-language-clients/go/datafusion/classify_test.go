@@ -0,0 +1,107 @@
+package datafusion
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want StatementKind
+	}{
+		{"SELECT 1", StatementRead},
+		{"  select * from t", StatementRead},
+		{"-- 注释\nSELECT 1", StatementRead},
+		{"/* INSERT */ SELECT 1", StatementRead},
+		{"(SELECT 1) UNION (SELECT 2)", StatementRead},
+		{"EXPLAIN SELECT 1", StatementRead},
+		{"EXPLAIN ANALYZE SELECT 1", StatementRead},
+		{"EXPLAIN ANALYZE INSERT INTO t VALUES (1)", StatementWrite},
+		{"SHOW TABLES", StatementRead},
+		{"INSERT INTO t VALUES (1)", StatementWrite},
+		{"CREATE TABLE t (a INT)", StatementWrite},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", StatementRead},
+		{"WITH x AS (SELECT 1) INSERT INTO t SELECT * FROM x", StatementWrite},
+		{"with x as (select 1), y as (select 2) delete from t where a in (select * from y)", StatementWrite},
+		{"WITH RECURSIVE r(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM r) UPDATE t SET a = 1", StatementWrite},
+		{"SELECT 'INSERT'", StatementRead},
+		{`SELECT "delete" FROM t`, StatementRead},
+		{"SET x = 1", StatementUnknown},
+		{"", StatementUnknown},
+		{"WITH x AS (SELECT 1)", StatementUnknown},
+		{";", StatementUnknown},
+	}
+	for _, tt := range tests {
+		if got := classifyStatement(tt.sql); got != tt.want {
+			t.Errorf("classifyStatement(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyMultiStatement(t *testing.T) {
+	tests := []struct {
+		sql       string
+		want      StatementKind
+		cacheable bool
+	}{
+		{"SELECT 1; DELETE FROM users", StatementWrite, false},
+		{"SELECT 1; DROP TABLE users;", StatementWrite, false},
+		{"WITH x AS (SELECT 1) SELECT * FROM x; UPDATE t SET a = 1", StatementWrite, false},
+		{"INSERT INTO t VALUES (1); SELECT * FROM t", StatementWrite, false},
+		{"SELECT 1; SET x = 1", StatementUnknown, false},
+		{"SELECT 1; SELECT 2", StatementRead, true},
+		{"SELECT 1;", StatementRead, true},
+		{"SELECT 1;;\n-- 结尾注释\n", StatementRead, true},
+		{"SELECT 1; SHOW TABLES", StatementRead, false},
+		// 引号、注释和括号内的分号不分隔语句
+		{"SELECT ';DELETE FROM users'", StatementRead, true},
+		{`SELECT "a;DROP" FROM t`, StatementRead, true},
+		{"SELECT 1 -- ; DELETE FROM users", StatementRead, true},
+		{"SELECT 1 /* ; DROP TABLE users */", StatementRead, true},
+		{"SELECT * FROM (SELECT 1; DELETE FROM users)", StatementRead, true},
+	}
+	for _, tt := range tests {
+		if got := classifyStatement(tt.sql); got != tt.want {
+			t.Errorf("classifyStatement(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+		if got := isIdempotent(tt.sql); got != (tt.want == StatementRead) {
+			t.Errorf("isIdempotent(%q) = %v", tt.sql, got)
+		}
+		if got := isCacheable(tt.sql); got != tt.cacheable {
+			t.Errorf("isCacheable(%q) = %v, want %v", tt.sql, got, tt.cacheable)
+		}
+	}
+}
+
+func TestReadWritePoolRoutesCTEWritesToPrimary(t *testing.T) {
+	primary := startServer(t, (&namedServer{name: RolePrimary}).fake())
+	replica := startServer(t, (&namedServer{name: RoleReplica}).fake())
+	p, err := NewReadWritePool(primary, []string{replica}, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewReadWritePool: %v", err)
+	}
+	defer p.Close()
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM t", RoleReplica},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", RoleReplica},
+		{"INSERT INTO t VALUES (1)", RolePrimary},
+		{"WITH x AS (SELECT 1) INSERT INTO t SELECT * FROM x", RolePrimary},
+		{"WITH x AS (SELECT id FROM s) UPDATE t SET a = 1 WHERE id IN (SELECT id FROM x)", RolePrimary},
+		{"SELECT 1; DELETE FROM users", RolePrimary},
+		{"SELECT 1; SELECT 2", RoleReplica},
+	}
+	for _, tt := range tests {
+		resp, err := p.ExecuteQuery(context.Background(), tt.sql)
+		if err != nil {
+			t.Fatalf("ExecuteQuery(%q): %v", tt.sql, err)
+		}
+		if resp.Result != tt.want {
+			t.Errorf("%q 发往 %s, want %s", tt.sql, resp.Result, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package datafusion 提供 DataFusion 查询服务的 Go 客户端。
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// QueryResponse 是一次查询的结果。
+type QueryResponse struct {
+	// QueryID 是服务端为查询分配的 ID
+	QueryID string
+	// RequestID 是本次调用发送的 x-request-id
+	RequestID string
+	// Result 是服务端返回的文本结果
+	Result string
+	// Columns 和 Rows 是结构化的结果集
+	Columns []Column
+	Rows    []Row
+
+	// rowsAffected 为 nil 表示语句没有报告影响行数
+	rowsAffected *int64
+}
+
+func newQueryResponse(ctx context.Context, resp *pb.QueryResponse, queryID string) *QueryResponse {
+	r := &QueryResponse{
+		QueryID:   queryID,
+		RequestID: requestID(ctx),
+		Result:    resp.GetResult(),
+		Columns:   columnsFromPB(resp.GetColumns()),
+		Rows:      rowsFromPB(resp.GetRows()),
+	}
+	if resp.RowsAffected != nil {
+		n := resp.GetRowsAffected()
+		r.rowsAffected = &n
+	}
+	return r
+}
+
+// RowsAffected 返回 DML/DDL 语句影响的行数，语句没有报告时第二个返回值为 false。
+func (r *QueryResponse) RowsAffected() (int64, bool) {
+	if r.rowsAffected == nil {
+		return 0, false
+	}
+	return *r.rowsAffected, true
+}
+
+// DataFusionClient 封装了到 DataFusion 服务的 gRPC 连接。
+// 客户端持有连接，使用完毕后需要调用 Close。
+type DataFusionClient struct {
+	conn     *grpc.ClientConn
+	rpc      pb.DataFusionClient
+	opts     options
+	tracer   trace.Tracer
+	metrics  *Metrics
+	cache    *resultCache
+	limiter  *rateLimiter
+	settings *sessionSettings
+	calls    inflight
+	active   activeQueries
+}
+
+// NewClient 连接到 target 并创建客户端。
+func NewClient(ctx context.Context, target string, opts ...Option) (*DataFusionClient, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialOpts, err := o.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts, grpc.WithStatsHandler(queryIDHandler{}))
+
+	var metrics *Metrics
+	if o.registerer != nil {
+		if metrics, err = NewMetrics(o.registerer); err != nil {
+			return nil, fmt.Errorf("注册指标失败: %w", err)
+		}
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(payloadHandler{m: metrics}))
+	}
+
+	comp, err := newCompressor(o.compression)
+	if err != nil {
+		return nil, err
+	}
+	if comp != nil {
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(comp.unaryInterceptor),
+			grpc.WithChainStreamInterceptor(comp.streamInterceptor),
+		)
+	}
+
+	settings, err := newSessionSettings(o.sessionDefaults)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts,
+		grpc.WithChainUnaryInterceptor(settings.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(settings.streamInterceptor),
+	)
+
+	if o.tenant != "" {
+		if err := validateMetadata(tenantHeader, o.tenant); err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, defaultMetadataInterceptors(tenantHeader, o.tenant)...)
+	}
+	if o.statementTimeout > 0 {
+		dialOpts = append(dialOpts, defaultMetadataInterceptors(statementTimeoutHeader, statementTimeoutMillis(o.statementTimeout))...)
+	}
+
+	if o.blockingDial {
+		dialOpts = append(dialOpts, grpc.WithBlock(), grpc.WithReturnConnectionError())
+	}
+
+	dialCtx := ctx
+	if o.dialTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, o.dialTimeout)
+		defer cancel()
+	}
+
+	conn, err := grpc.DialContext(dialCtx, target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("连接 %s 失败: %w", target, err)
+	}
+	go watchState(conn, metrics, o.logger, o.reconnect)
+
+	return &DataFusionClient{
+		conn:     conn,
+		rpc:      pb.NewDataFusionClient(conn),
+		opts:     o,
+		tracer:   newTracer(o.tracerProvider),
+		metrics:  metrics,
+		cache:    newResultCache(o.cacheSize, o.cacheTTL),
+		limiter:  newRateLimiter(&o, metrics),
+		settings: settings,
+	}, nil
+}
+
+// Close 关闭底层连接。
+func (c *DataFusionClient) Close() error {
+	c.calls.close()
+	return c.conn.Close()
+}
+
+// ExecuteQuery 执行一条 SQL 查询，失败时返回 *QueryError。
+// 配置了 WithRetry 时，只读查询遇到临时故障会自动重试；
+// 配置了 WithResultCache 时，缓存命中的 SELECT 不访问服务端；
+// 指定了 QueryOption 的调用不使用缓存。
+func (c *DataFusionClient) ExecuteQuery(ctx context.Context, sql string, opts ...QueryOption) (*QueryResponse, error) {
+	if len(opts) > 0 {
+		ctx, err := applyQueryOptions(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return c.query(ctx, &pb.QueryRequest{Sql: sql})
+	}
+	// 关闭后即使缓存命中也拒绝调用
+	if c.calls.closed() {
+		return nil, ErrClientClosed
+	}
+	if resp, ok := c.cache.get(sql); ok {
+		hit := *resp
+		hit.RequestID = requestID(ensureRequestID(ctx))
+		return &hit, nil
+	}
+	resp, err := c.query(ctx, &pb.QueryRequest{Sql: sql})
+	if err != nil {
+		return nil, err
+	}
+	c.cache.put(sql, resp)
+	return resp, nil
+}
+
+// query 执行一次非流式查询，记录追踪与指标
+func (c *DataFusionClient) query(ctx context.Context, req *pb.QueryRequest) (*QueryResponse, error) {
+	return c.runQuery(ctx, req, c.opts.queryTimeout)
+}
+
+// runQuery 与 query 相同，但以 timeout 代替 WithQueryTimeout，
+// timeout 为 0 时只受 ctx 的截止时间限制
+func (c *DataFusionClient) runQuery(ctx context.Context, req *pb.QueryRequest, timeout time.Duration) (*QueryResponse, error) {
+	sql := req.GetSql()
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
+	var resp *QueryResponse
+	err = c.instrument(ctx, sql, func(ctx context.Context) (int, error) {
+		r, queryID, err := c.executeQuery(ctx, req)
+		if err != nil {
+			return 0, newQueryError(ctx, sql, err)
+		}
+		resp = newQueryResponse(ctx, r, queryID)
+		return len(r.GetRows()), nil
+	})
+	return resp, err
+}
+
+// instrument 以一次非流式查询执行 call：登记为进行中的查询以便 CancelAll 终止，
+// 并记录 datafusion.query span、指标、日志和 Hooks。
+// call 返回收到的行数和最终返回给调用方的错误
+func (c *DataFusionClient) instrument(ctx context.Context, sql string, call func(ctx context.Context) (int, error)) error {
+	ctx, untrack := c.track(ctx)
+	defer untrack()
+
+	ctx, span := c.startSpan(ctx, sql)
+	c.opts.hooks.queryStart(ctx, sql)
+	start := time.Now()
+	rows, err := call(ctx)
+	dur := time.Since(start)
+	endSpan(span, rows, err, dur)
+	c.metrics.observe(ctx, methodUnary, err, dur)
+	c.logQuery(ctx, methodUnary, sql, err, dur)
+	c.opts.hooks.queryEnd(ctx, sql, rows, err, dur)
+	return err
+}
+
+// executeQuery 发送 ExecuteQuery RPC，按重试策略处理临时故障。
+// 调用方上下文结束时会通知服务端终止查询。
+func (c *DataFusionClient) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, string, error) {
+	var (
+		resp   *pb.QueryResponse
+		header metadata.MD
+	)
+	err := c.withRetry(ctx, isIdempotent(req.GetSql()), func() error {
+		var err error
+		header = nil
+		resp, err = c.rpc.ExecuteQuery(ctx, req, grpc.Header(&header))
+		if err != nil && ctx.Err() != nil {
+			if id, ok := activeQueryFrom(ctx).claimCancel(queryIDFromHeader(header)); ok {
+				c.cancelOnServer(id)
+			}
+		}
+		return err
+	})
+	return resp, queryIDFromHeader(header), err
+}
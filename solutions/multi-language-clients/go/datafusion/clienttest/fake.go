@@ -0,0 +1,108 @@
+// Package clienttest 提供在单元测试中替代 DataFusion 客户端的工具。
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"datafusion-client/datafusion"
+)
+
+// Call 记录一次对 FakeQuerier 的调用。
+type Call struct {
+	// Method 是被调用的方法名，如 ExecuteQuery
+	Method string
+	SQL    string
+}
+
+type response struct {
+	resp    *datafusion.QueryResponse
+	batches []*datafusion.RowBatch
+	err     error
+}
+
+// FakeQuerier 是按 SQL 返回预设结果的 datafusion.Querier，不需要网络。
+// 零值可直接使用，可并发调用。
+type FakeQuerier struct {
+	mu        sync.Mutex
+	responses map[string]response
+	calls     []Call
+}
+
+var _ datafusion.Querier = (*FakeQuerier)(nil)
+
+// SetResponse 设置 sql 的查询结果。
+func (f *FakeQuerier) SetResponse(sql string, resp *datafusion.QueryResponse) {
+	f.set(sql, response{resp: resp})
+}
+
+// SetStream 设置 sql 的流式查询结果。
+func (f *FakeQuerier) SetStream(sql string, batches ...*datafusion.RowBatch) {
+	f.set(sql, response{batches: batches})
+}
+
+// SetError 让 sql 的查询返回 err。
+func (f *FakeQuerier) SetError(sql string, err error) {
+	f.set(sql, response{err: err})
+}
+
+func (f *FakeQuerier) set(sql string, r response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.responses == nil {
+		f.responses = make(map[string]response)
+	}
+	f.responses[sql] = r
+}
+
+// Calls 返回按调用顺序记录的所有调用。
+func (f *FakeQuerier) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset 清除预设结果和调用记录。
+func (f *FakeQuerier) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = nil
+	f.calls = nil
+}
+
+// ExecuteQuery 返回为 sql 预设的结果，未预设时返回错误。opts 被忽略。
+func (f *FakeQuerier) ExecuteQuery(ctx context.Context, sql string, opts ...datafusion.QueryOption) (*datafusion.QueryResponse, error) {
+	r, err := f.lookup(ctx, "ExecuteQuery", sql)
+	if err != nil {
+		return nil, err
+	}
+	if r.resp == nil {
+		return &datafusion.QueryResponse{}, nil
+	}
+	return r.resp, nil
+}
+
+// ExecuteQueryStream 返回为 sql 预设的批次组成的结果流。opts 被忽略。
+func (f *FakeQuerier) ExecuteQueryStream(ctx context.Context, sql string, opts ...datafusion.QueryOption) (*datafusion.ResultStream, error) {
+	r, err := f.lookup(ctx, "ExecuteQueryStream", sql)
+	if err != nil {
+		return nil, err
+	}
+	return datafusion.NewResultStream(r.batches...), nil
+}
+
+func (f *FakeQuerier) lookup(ctx context.Context, method, sql string) (response, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Method: method, SQL: sql})
+	r, ok := f.responses[sql]
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return response{}, err
+	}
+	if !ok {
+		return response{}, fmt.Errorf("clienttest: 未设置 %q 的结果", sql)
+	}
+	return r, r.err
+}
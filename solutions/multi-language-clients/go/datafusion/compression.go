@@ -0,0 +1,138 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // 注册 gzip 压缩
+	"google.golang.org/grpc/status"
+)
+
+// 已注册的压缩算法名
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+func init() {
+	if encoding.GetCompressor(CompressionZstd) == nil {
+		encoding.RegisterCompressor(zstdCompressor{})
+	}
+}
+
+// WithCompression 使用 name 指定的算法 (gzip 或 zstd) 压缩每次调用的请求；
+// 服务端通常以相同算法压缩响应。
+// 服务端不支持该算法时，非流式调用会自动以不压缩的方式重发，
+// 此后该客户端不再压缩。
+func WithCompression(name string) Option {
+	return func(o *options) {
+		o.compression = name
+	}
+}
+
+// compressor 为每次调用附加压缩选项，并在服务端拒绝后退回不压缩
+type compressor struct {
+	name     string
+	disabled atomic.Bool
+}
+
+func newCompressor(name string) (*compressor, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if encoding.GetCompressor(name) == nil {
+		return nil, fmt.Errorf("不支持的压缩算法: %q", name)
+	}
+	return &compressor{name: name}, nil
+}
+
+// isCompressionRejected 判断错误是否表示服务端不支持请求使用的压缩算法
+func isCompressionRejected(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Unimplemented {
+		return false
+	}
+	msg := strings.ToLower(st.Message())
+	return strings.Contains(msg, "grpc-encoding") || strings.Contains(msg, "compress")
+}
+
+func (c *compressor) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if c.disabled.Load() {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(c.name))...)
+	if isCompressionRejected(err) {
+		// 服务端未能解压请求，查询并未执行，可以安全重发
+		c.disabled.Store(true)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return err
+}
+
+func (c *compressor) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if c.disabled.Load() {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	s, err := streamer(ctx, desc, cc, method, append(opts, grpc.UseCompressor(c.name))...)
+	if err != nil {
+		if isCompressionRejected(err) {
+			c.disabled.Store(true)
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		return nil, err
+	}
+	return &compressedStream{ClientStream: s, compressor: c}, nil
+}
+
+// compressedStream 在流中观察到服务端拒绝压缩时关闭后续调用的压缩
+type compressedStream struct {
+	grpc.ClientStream
+	compressor *compressor
+}
+
+func (s *compressedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if isCompressionRejected(err) {
+		s.compressor.disabled.Store(true)
+	}
+	return err
+}
+
+// zstdCompressor 实现 gRPC 的 zstd 编码
+type zstdCompressor struct{}
+
+func (zstdCompressor) Name() string {
+	return CompressionZstd
+}
+
+func (zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+func (zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return zstdReader{dec}, nil
+}
+
+// zstdReader 读到结尾时释放解码器
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func (r zstdReader) Read(p []byte) (int, error) {
+	n, err := r.Decoder.Read(p)
+	if err == io.EOF {
+		r.Decoder.Close()
+	}
+	return n, err
+}
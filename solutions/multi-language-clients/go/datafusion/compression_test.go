@@ -0,0 +1,152 @@
+package datafusion
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// encodingRecorder 是服务端 stats.Handler，记录每次调用请求头中的 grpc-encoding
+type encodingRecorder struct {
+	mu        sync.Mutex
+	encodings []string
+}
+
+type encodingKey struct{}
+
+func (r *encodingRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, encodingKey{}, new(string))
+}
+
+func (r *encodingRecorder) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	in, ok := s.(*stats.InHeader)
+	if !ok || in.IsClient() {
+		return
+	}
+	*ctx.Value(encodingKey{}).(*string) = in.Compression
+	r.mu.Lock()
+	r.encodings = append(r.encodings, in.Compression)
+	r.mu.Unlock()
+}
+
+func (r *encodingRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *encodingRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *encodingRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.encodings...)
+}
+
+// requestEncoding 返回当前调用请求使用的压缩算法，不压缩时为空
+func requestEncoding(ctx context.Context) string {
+	return *ctx.Value(encodingKey{}).(*string)
+}
+
+// serveWithEncodingRecorder 启动记录 grpc-encoding 的服务端，返回监听地址
+func serveWithEncodingRecorder(t *testing.T, rec *encodingRecorder, srv pb.DataFusionServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer(grpc.StatsHandler(rec))
+	pb.RegisterDataFusionServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestWithCompressionRoundTrip(t *testing.T) {
+	for _, name := range []string{CompressionGzip, CompressionZstd} {
+		t.Run(name, func(t *testing.T) {
+			rec := &encodingRecorder{}
+			var gotSQL string
+			addr := serveWithEncodingRecorder(t, rec, &fakeServer{
+				executeQuery: func(_ context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+					gotSQL = req.GetSql()
+					return &pb.QueryResponse{Rows: []*pb.Row{{Values: []*pb.Value{mustPBValue("ok")}}}}, nil
+				},
+			})
+			c := dialTestClient(t, addr, WithCompression(name))
+
+			const sql = "SELECT 'ok' FROM compressed"
+			resp, err := c.ExecuteQuery(context.Background(), sql)
+			if err != nil {
+				t.Fatalf("ExecuteQuery: %v", err)
+			}
+			if gotSQL != sql || len(resp.Rows) != 1 {
+				t.Errorf("服务端收到 %q、客户端收到 %d 行, want %q 和 1 行", gotSQL, len(resp.Rows), sql)
+			}
+			if got := rec.snapshot(); len(got) != 1 || got[0] != name {
+				t.Errorf("grpc-encoding = %q, want [%s]", got, name)
+			}
+		})
+	}
+}
+
+func TestWithCompressionFallsBackToIdentity(t *testing.T) {
+	rec := &encodingRecorder{}
+	addr := serveWithEncodingRecorder(t, rec, &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			// 模拟没有安装 gzip 解压器的服务端
+			if enc := requestEncoding(ctx); enc != "" {
+				return nil, status.Errorf(codes.Unimplemented, "grpc: Decompressor is not installed for grpc-encoding %q", enc)
+			}
+			return &pb.QueryResponse{}, nil
+		},
+	})
+	c := dialTestClient(t, addr, WithCompression(CompressionGzip))
+
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("服务端拒绝压缩后应以不压缩的方式重发: %v", err)
+	}
+	if _, err := c.ExecuteQuery(ctx, "SELECT 2"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	// 首次调用被拒绝后重发，此后不再压缩
+	want := []string{CompressionGzip, "", ""}
+	got := rec.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("grpc-encoding = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 次调用 grpc-encoding = %q, want %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestWithCompressionOtherUnimplementedNotRetried(t *testing.T) {
+	rec := &encodingRecorder{}
+	addr := serveWithEncodingRecorder(t, rec, &fakeServer{})
+	c := dialTestClient(t, addr, WithCompression(CompressionGzip))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("ExecuteQuery = %v, want Unimplemented", err)
+	}
+	if got := rec.snapshot(); len(got) != 1 {
+		t.Errorf("与压缩无关的 Unimplemented 不应重发，服务端收到 %d 次调用", len(got))
+	}
+}
+
+func TestWithCompressionUnknownAlgorithm(t *testing.T) {
+	c, err := NewClient(context.Background(), "127.0.0.1:1", WithInsecure(), WithCompression("brotli"))
+	if err == nil {
+		c.Close()
+		t.Fatal("未注册的压缩算法应使 NewClient 返回错误")
+	}
+}
@@ -0,0 +1,251 @@
+package datafusion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 是从配置文件和环境变量加载的客户端配置。
+type Config struct {
+	// Servers 是 host:port 形式的服务端地址，多个地址时以 round_robin 分发请求
+	Servers []string
+	// Cluster 不为空时把 Servers 作为种子节点，连接 datafusion:///Cluster
+	Cluster string
+	TLS     TLSConfig
+	// Insecure 为 true 时使用明文连接
+	Insecure     bool
+	DialTimeout  time.Duration
+	QueryTimeout time.Duration
+	// Token 是每次调用发送的 Bearer 令牌
+	Token string
+}
+
+// TLSConfig 是 Config 中的证书配置，均为文件路径。
+type TLSConfig struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+// fileConfig 是配置文件的格式，时长写作 10s、1m30s 等
+type fileConfig struct {
+	Servers []string `json:"servers" yaml:"servers"`
+	Cluster string   `json:"cluster" yaml:"cluster"`
+	TLS     struct {
+		CAFile     string `json:"ca_file" yaml:"ca_file"`
+		CertFile   string `json:"cert_file" yaml:"cert_file"`
+		KeyFile    string `json:"key_file" yaml:"key_file"`
+		ServerName string `json:"server_name" yaml:"server_name"`
+	} `json:"tls" yaml:"tls"`
+	Insecure     bool   `json:"insecure" yaml:"insecure"`
+	DialTimeout  string `json:"dial_timeout" yaml:"dial_timeout"`
+	QueryTimeout string `json:"query_timeout" yaml:"query_timeout"`
+	Token        string `json:"token" yaml:"token"`
+}
+
+// LoadConfig 读取 path 指向的 YAML 或 JSON 配置文件 (按扩展名 .json 区分)，
+// 再以 DATAFUSION_* 环境变量覆盖，如 DATAFUSION_SERVERS=a:50051,b:50051、
+// DATAFUSION_TLS_CA_FILE、DATAFUSION_QUERY_TIMEOUT=30s。
+// path 为空时只读取环境变量。
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if cfg, err = parseConfig(data, strings.EqualFold(filepath.Ext(path), ".json")); err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func parseConfig(data []byte, isJSON bool) (*Config, error) {
+	var fc fileConfig
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&fc); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// 空文件视为空配置
+		if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{
+		Servers: fc.Servers,
+		Cluster: fc.Cluster,
+		TLS: TLSConfig{
+			CAFile:     fc.TLS.CAFile,
+			CertFile:   fc.TLS.CertFile,
+			KeyFile:    fc.TLS.KeyFile,
+			ServerName: fc.TLS.ServerName,
+		},
+		Insecure: fc.Insecure,
+		Token:    fc.Token,
+	}
+	var err error
+	if cfg.DialTimeout, err = parseConfigDuration("dial_timeout", fc.DialTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.QueryTimeout, err = parseConfigDuration("query_timeout", fc.QueryTimeout); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func parseConfigDuration(key, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("配置项 %s: 无效的时长 %q", key, v)
+	}
+	return d, nil
+}
+
+// applyEnv 以环境变量覆盖配置，lookup 通常为 os.LookupEnv
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	strs := []struct {
+		name string
+		dst  *string
+	}{
+		{"DATAFUSION_CLUSTER", &c.Cluster},
+		{"DATAFUSION_TLS_CA_FILE", &c.TLS.CAFile},
+		{"DATAFUSION_TLS_CERT_FILE", &c.TLS.CertFile},
+		{"DATAFUSION_TLS_KEY_FILE", &c.TLS.KeyFile},
+		{"DATAFUSION_TLS_SERVER_NAME", &c.TLS.ServerName},
+		{"DATAFUSION_TOKEN", &c.Token},
+	}
+	for _, s := range strs {
+		if v, ok := lookup(s.name); ok {
+			*s.dst = v
+		}
+	}
+
+	if v, ok := lookup("DATAFUSION_SERVERS"); ok {
+		c.Servers = nil
+		for _, addr := range strings.Split(v, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				c.Servers = append(c.Servers, addr)
+			}
+		}
+	}
+	if v, ok := lookup("DATAFUSION_INSECURE"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("环境变量 DATAFUSION_INSECURE: 无效的布尔值 %q", v)
+		}
+		c.Insecure = b
+	}
+
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"DATAFUSION_DIAL_TIMEOUT", &c.DialTimeout},
+		{"DATAFUSION_QUERY_TIMEOUT", &c.QueryTimeout},
+	}
+	for _, d := range durations {
+		v, ok := lookup(d.name)
+		if !ok {
+			continue
+		}
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s: 无效的时长 %q", d.name, v)
+		}
+		*d.dst = dur
+	}
+	return nil
+}
+
+// Validate 检查配置是否完整、一致，错误信息中给出出错的配置项。
+func (c *Config) Validate() error {
+	if len(c.Servers) == 0 {
+		return errors.New("缺少配置项 servers (或环境变量 DATAFUSION_SERVERS)")
+	}
+	for i, addr := range c.Servers {
+		if strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("配置项 servers[%d] 为空", i)
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("配置项 tls.cert_file 和 tls.key_file 必须同时设置")
+	}
+	if c.Insecure && (c.TLS.CAFile != "" || c.TLS.CertFile != "") {
+		return errors.New("配置项 insecure 不能与 tls 证书同时设置")
+	}
+	if c.DialTimeout < 0 {
+		return errors.New("配置项 dial_timeout 不能为负")
+	}
+	if c.QueryTimeout < 0 {
+		return errors.New("配置项 query_timeout 不能为负")
+	}
+	return nil
+}
+
+// NewClientFromConfig 按配置创建客户端，opts 在配置之后应用，可覆盖配置中的设置。
+func NewClientFromConfig(ctx context.Context, cfg *Config, opts ...Option) (*DataFusionClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var o []Option
+	switch {
+	case cfg.Insecure:
+		o = append(o, WithInsecure())
+	case cfg.TLS.CAFile != "" || cfg.TLS.CertFile != "":
+		o = append(o, WithTLSFromFiles(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile))
+	}
+	if cfg.TLS.ServerName != "" {
+		o = append(o, WithServerName(cfg.TLS.ServerName))
+	}
+	if cfg.DialTimeout > 0 {
+		o = append(o, WithDialTimeout(cfg.DialTimeout))
+	}
+	if cfg.QueryTimeout > 0 {
+		o = append(o, WithQueryTimeout(cfg.QueryTimeout))
+	}
+	if cfg.Token != "" {
+		o = append(o, WithStaticToken(cfg.Token))
+	}
+
+	var target string
+	switch {
+	case cfg.Cluster != "":
+		o = append(o, WithClusterResolver(cfg.Servers, 0))
+		target = ClusterScheme + ":///" + cfg.Cluster
+	case len(cfg.Servers) == 1:
+		target = cfg.Servers[0]
+	default:
+		o = append(o, withStaticAddresses(cfg.Servers))
+		target = staticScheme + ":///"
+	}
+	return NewClient(ctx, target, append(o, opts...)...)
+}
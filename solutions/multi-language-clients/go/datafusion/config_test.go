@@ -0,0 +1,161 @@
+package datafusion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// writeConfig 把 content 写入临时目录下的 name 并返回路径
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return path
+}
+
+const testConfigYAML = `
+servers:
+  - file-a:50051
+  - file-b:50051
+tls:
+  ca_file: /etc/datafusion/ca.pem
+  server_name: datafusion.internal
+dial_timeout: 5s
+query_timeout: 30s
+token: file-token
+`
+
+func TestLoadConfigFile(t *testing.T) {
+	cfg, err := LoadConfig(writeConfig(t, "client.yaml", testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if strings.Join(cfg.Servers, ",") != "file-a:50051,file-b:50051" {
+		t.Errorf("Servers = %v", cfg.Servers)
+	}
+	if cfg.TLS.CAFile != "/etc/datafusion/ca.pem" || cfg.TLS.ServerName != "datafusion.internal" {
+		t.Errorf("TLS = %+v", cfg.TLS)
+	}
+	if cfg.DialTimeout != 5*time.Second || cfg.QueryTimeout != 30*time.Second || cfg.Token != "file-token" {
+		t.Errorf("DialTimeout = %v, QueryTimeout = %v, Token = %q", cfg.DialTimeout, cfg.QueryTimeout, cfg.Token)
+	}
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	t.Setenv("DATAFUSION_SERVERS", "env-a:50051, env-b:50051,")
+	t.Setenv("DATAFUSION_QUERY_TIMEOUT", "1m")
+	t.Setenv("DATAFUSION_TOKEN", "env-token")
+	t.Setenv("DATAFUSION_TLS_SERVER_NAME", "")
+
+	cfg, err := LoadConfig(writeConfig(t, "client.yaml", testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if strings.Join(cfg.Servers, ",") != "env-a:50051,env-b:50051" {
+		t.Errorf("Servers = %v, want 环境变量中的地址", cfg.Servers)
+	}
+	if cfg.QueryTimeout != time.Minute || cfg.Token != "env-token" {
+		t.Errorf("QueryTimeout = %v, Token = %q, want 1m, env-token", cfg.QueryTimeout, cfg.Token)
+	}
+	// 设置为空串的环境变量同样覆盖文件
+	if cfg.TLS.ServerName != "" {
+		t.Errorf("TLS.ServerName = %q, want 空串", cfg.TLS.ServerName)
+	}
+	// 未设置环境变量的配置项保留文件中的值
+	if cfg.DialTimeout != 5*time.Second || cfg.TLS.CAFile != "/etc/datafusion/ca.pem" {
+		t.Errorf("DialTimeout = %v, TLS.CAFile = %q, want 文件中的值", cfg.DialTimeout, cfg.TLS.CAFile)
+	}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfig(t, "client.json", `{"servers": ["json:50051"], "insecure": true, "query_timeout": "2s"}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Servers) != 1 || cfg.Servers[0] != "json:50051" || !cfg.Insecure || cfg.QueryTimeout != 2*time.Second {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadConfigEnvOnly(t *testing.T) {
+	t.Setenv("DATAFUSION_SERVERS", "env:50051")
+	t.Setenv("DATAFUSION_INSECURE", "true")
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Servers) != 1 || cfg.Servers[0] != "env:50051" || !cfg.Insecure {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+	}{
+		{name: "YAML 格式错误", file: "client.yaml", content: "servers: [a:50051\ntls: {"},
+		{name: "YAML 未知配置项", file: "client.yaml", content: "servers: [a:50051]\nretries: 3\n"},
+		{name: "JSON 格式错误", file: "client.json", content: `{"servers": [`},
+		{name: "无效的时长", file: "client.yaml", content: "servers: [a:50051]\nquery_timeout: soon\n"},
+		{name: "环境变量无效的布尔值", env: map[string]string{"DATAFUSION_SERVERS": "a:50051", "DATAFUSION_INSECURE": "maybe"}},
+		{name: "环境变量无效的时长", env: map[string]string{"DATAFUSION_SERVERS": "a:50051", "DATAFUSION_DIAL_TIMEOUT": "5"}},
+		{name: "缺少 servers", file: "client.yaml", content: "insecure: true\n"},
+		{name: "证书缺少私钥", file: "client.yaml", content: "servers: [a:50051]\ntls:\n  cert_file: c.pem\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, tt.file, tt.content)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("LoadConfig 应返回错误")
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("配置文件不存在时应返回错误")
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	addr := startServer(t, &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{}, nil
+		},
+	})
+	t.Setenv("DATAFUSION_SERVERS", addr)
+	t.Setenv("DATAFUSION_INSECURE", "true")
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	c, err := NewClientFromConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClientFromConfig: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Errorf("ExecuteQuery: %v", err)
+	}
+}
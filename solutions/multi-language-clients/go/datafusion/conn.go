@@ -0,0 +1,105 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ErrClientClosed 表示客户端连接已关闭。
+var ErrClientClosed = errors.New("客户端已关闭")
+
+// WithKeepalive 在连接空闲 interval 后发送 keepalive ping，
+// 超过 timeout 未收到响应即认为连接已断开。
+// permitWithoutStream 为 true 时没有进行中的 RPC 也会发送 ping。
+// 服务端的 keepalive.EnforcementPolicy 需要允许同样的频率。
+func WithKeepalive(interval, timeout time.Duration, permitWithoutStream bool) Option {
+	return func(o *options) {
+		o.keepalive = &keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             timeout,
+			PermitWithoutStream: permitWithoutStream,
+		}
+	}
+}
+
+// WithBlockingDial 让 NewClient 等待连接就绪后再返回，
+// 等待时间受 WithDialTimeout 限制，超时返回最近一次的连接错误。
+// 默认情况下 NewClient 立即返回，首次查询时才建立连接。
+func WithBlockingDial() Option {
+	return func(o *options) {
+		o.blockingDial = true
+	}
+}
+
+// State 返回底层连接的当前状态。
+func (c *DataFusionClient) State() connectivity.State {
+	return c.conn.GetState()
+}
+
+// watchState 记录连接状态的每次变化，连接关闭后退出。
+// 连接就绪过之后的每次 CONNECTING 视为一次重连，
+// reconnect 为 true 时连接断开进入 IDLE 后立即发起重连
+func watchState(conn *grpc.ClientConn, m *Metrics, log Logger, reconnect bool) {
+	state := conn.GetState()
+	// wasReady 表示连接曾经就绪，attempts 是本次断开后的重连次数
+	wasReady := state == connectivity.Ready
+	attempts := 0
+	for state != connectivity.Shutdown {
+		if !conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+		state = conn.GetState()
+		m.observeState(state)
+
+		switch state {
+		case connectivity.TransientFailure:
+			log.Warn("连接失败，等待重连", "target", conn.Target())
+		case connectivity.Ready:
+			if attempts > 0 {
+				log.Info("重连成功", "target", conn.Target(), "attempts", attempts)
+			} else {
+				log.Info("连接状态变化", "target", conn.Target(), "state", state.String())
+			}
+			wasReady = true
+			attempts = 0
+		case connectivity.Connecting:
+			if wasReady {
+				attempts++
+				m.observeReconnect()
+				log.Info("尝试重连", "target", conn.Target(), "attempt", attempts)
+			} else {
+				log.Debug("连接状态变化", "target", conn.Target(), "state", state.String())
+			}
+		case connectivity.Idle:
+			log.Debug("连接状态变化", "target", conn.Target(), "state", state.String())
+			if wasReady && reconnect {
+				conn.Connect()
+			}
+		case connectivity.Shutdown:
+			log.Info("连接状态变化", "target", conn.Target(), "state", state.String())
+		}
+	}
+}
+
+// WaitForReady 阻塞直到连接就绪或 ctx 结束。
+func (c *DataFusionClient) WaitForReady(ctx context.Context) error {
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return ErrClientClosed
+		case connectivity.Idle:
+			c.conn.Connect()
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
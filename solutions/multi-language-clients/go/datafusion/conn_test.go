@@ -0,0 +1,136 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+
+	"datafusion-client/pb"
+)
+
+func TestWithKeepaliveParams(t *testing.T) {
+	o := defaultOptions()
+	WithKeepalive(30*time.Second, 5*time.Second, true)(&o)
+
+	want := keepalive.ClientParameters{Time: 30 * time.Second, Timeout: 5 * time.Second, PermitWithoutStream: true}
+	if o.keepalive == nil || *o.keepalive != want {
+		t.Fatalf("keepalive = %+v, want %+v", o.keepalive, want)
+	}
+	if defaultOptions().keepalive != nil {
+		t.Error("默认不应启用 keepalive")
+	}
+}
+
+func TestWithKeepaliveDialsServerWithEnforcementPolicy(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             10 * time.Second,
+		PermitWithoutStream: true,
+	}))
+	pb.RegisterDataFusionServer(s, &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{}, nil
+		},
+	})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	c := dialTestClient(t, lis.Addr().String(), WithKeepalive(10*time.Second, time.Second, true))
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("启用 keepalive 后查询失败: %v", err)
+	}
+	if got := c.State(); got != connectivity.Ready {
+		t.Errorf("State = %s, want READY", got)
+	}
+}
+
+func TestWaitForReadyReturnsOnceReady(t *testing.T) {
+	c := newTestClient(t, &fakeServer{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForReady(ctx); err != nil {
+		t.Fatalf("WaitForReady: %v", err)
+	}
+	if got := c.State(); got != connectivity.Ready {
+		t.Errorf("State = %s, want READY", got)
+	}
+}
+
+func TestWaitForReadyWaitsForServer(t *testing.T) {
+	addr := unusedAddr(t)
+	c := dialTestClient(t, addr, WithReconnect(50*time.Millisecond))
+
+	errc := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		errc <- c.WaitForReady(ctx)
+	}()
+
+	// 服务端稍后才启动，WaitForReady 应持续等待而不是因首次连接失败返回
+	time.Sleep(100 * time.Millisecond)
+	serve(t, addr, &fakeServer{})
+	if err := <-errc; err != nil {
+		t.Fatalf("WaitForReady: %v", err)
+	}
+}
+
+func TestWaitForReadyContextDone(t *testing.T) {
+	c := dialTestClient(t, unusedAddr(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := c.WaitForReady(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForReady = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForReadyClosedClient(t *testing.T) {
+	c := newTestClient(t, &fakeServer{})
+	c.Close()
+
+	if err := c.WaitForReady(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("WaitForReady = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestWithBlockingDialUnreachable(t *testing.T) {
+	start := time.Now()
+	c, err := NewClient(context.Background(), unusedAddr(t),
+		WithInsecure(), WithBlockingDial(), WithDialTimeout(300*time.Millisecond))
+	if err == nil {
+		c.Close()
+		t.Fatal("连接不可达地址时 NewClient 应在超时后返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("NewClient 用时 %v，未受 WithDialTimeout 限制", elapsed)
+	}
+	// 返回最近一次的连接错误，而不只是 context deadline exceeded
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("错误应包含最近一次的连接错误: %v", err)
+	}
+}
+
+func TestWithBlockingDialReady(t *testing.T) {
+	addr := startServer(t, &fakeServer{})
+	c, err := NewClient(context.Background(), addr,
+		WithInsecure(), WithBlockingDial(), WithDialTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	if got := c.State(); got != connectivity.Ready {
+		t.Errorf("阻塞建连返回后 State = %s, want READY", got)
+	}
+}
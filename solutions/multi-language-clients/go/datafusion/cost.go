@@ -0,0 +1,66 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+
+	"datafusion-client/pb"
+)
+
+// CostEstimate 是服务端规划器对查询代价的估算。
+type CostEstimate struct {
+	// Known 为 false 表示规划器无法估算该查询，其余字段为零值
+	Known bool
+	// RowsScanned 是预计扫描的行数
+	RowsScanned int64
+	// Partitions 是预计涉及的分区数
+	Partitions int
+	// Cost 是相对代价分值，只用于比较不同查询
+	Cost float64
+	// Reason 是无法估算时服务端给出的原因
+	Reason string
+}
+
+func (e *CostEstimate) String() string {
+	if !e.Known {
+		if e.Reason == "" {
+			return "无法估算代价"
+		}
+		return "无法估算代价: " + e.Reason
+	}
+	return fmt.Sprintf("预计扫描 %d 行，涉及 %d 个分区，代价 %.2f", e.RowsScanned, e.Partitions, e.Cost)
+}
+
+// EstimateCost 只让服务端生成执行计划并估算代价，不执行查询。
+// 规划器无法估算时返回 Known 为 false 的估算而不是错误。
+func (c *DataFusionClient) EstimateCost(ctx context.Context, sql string) (*CostEstimate, error) {
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	var resp *pb.CostEstimate
+	// 估算不执行语句，可以安全重试
+	err = c.withRetry(ctx, true, func() error {
+		var err error
+		resp, err = c.rpc.EstimateCost(ctx, &pb.EstimateCostRequest{Sql: sql})
+		return err
+	})
+	if err != nil {
+		return nil, newQueryError(ctx, sql, err)
+	}
+	if !resp.GetKnown() {
+		return &CostEstimate{Reason: resp.GetReason()}, nil
+	}
+	return &CostEstimate{
+		Known:       true,
+		RowsScanned: resp.GetRowsScanned(),
+		Partitions:  int(resp.GetPartitions()),
+		Cost:        resp.GetCost(),
+	}, nil
+}
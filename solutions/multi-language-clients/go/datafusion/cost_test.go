@@ -0,0 +1,80 @@
+package datafusion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"datafusion-client/pb"
+)
+
+// plannerServer 模拟规划器：全表扫描 events 表，主键等值查询只扫描一行
+type plannerServer struct {
+	executed int
+}
+
+func (s *plannerServer) fake() *fakeServer {
+	return &fakeServer{
+		estimateCost: s.estimateCost,
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *plannerServer) estimateCost(ctx context.Context, req *pb.EstimateCostRequest) (*pb.CostEstimate, error) {
+	sql := strings.ToUpper(req.GetSql())
+	switch {
+	case !strings.Contains(sql, "FROM EVENTS"):
+		return &pb.CostEstimate{Reason: "未知的表"}, nil
+	case strings.Contains(sql, "WHERE ID ="):
+		return &pb.CostEstimate{Known: true, RowsScanned: 1, Partitions: 1, Cost: 1.5}, nil
+	default:
+		return &pb.CostEstimate{Known: true, RowsScanned: 50_000_000, Partitions: 64, Cost: 9.2e6}, nil
+	}
+}
+
+func (s *plannerServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	s.executed++
+	return &pb.QueryResponse{}, nil
+}
+
+func TestEstimateCostFullScanCostsMore(t *testing.T) {
+	srv := &plannerServer{}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	scan, err := c.EstimateCost(ctx, "SELECT * FROM events")
+	if err != nil {
+		t.Fatalf("EstimateCost: %v", err)
+	}
+	lookup, err := c.EstimateCost(ctx, "SELECT * FROM events WHERE id = 42")
+	if err != nil {
+		t.Fatalf("EstimateCost: %v", err)
+	}
+	if !scan.Known || !lookup.Known {
+		t.Fatalf("估算未知: %v / %v", scan, lookup)
+	}
+	if scan.Cost <= lookup.Cost || scan.RowsScanned <= lookup.RowsScanned {
+		t.Errorf("全表扫描 %v 的代价应高于点查 %v", scan, lookup)
+	}
+	if scan.Partitions != 64 {
+		t.Errorf("Partitions = %d, want 64", scan.Partitions)
+	}
+	if srv.executed != 0 {
+		t.Errorf("估算代价时执行了 %d 次查询", srv.executed)
+	}
+}
+
+func TestEstimateCostUnknown(t *testing.T) {
+	c := newTestClient(t, (&plannerServer{}).fake())
+
+	est, err := c.EstimateCost(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("EstimateCost: %v", err)
+	}
+	if est.Known || est.Reason != "未知的表" {
+		t.Errorf("估算 = %+v", est)
+	}
+	if got := est.String(); got != "无法估算代价: 未知的表" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// ErrCursorExpired 表示游标的续页令牌已在服务端过期，需要重新执行查询。
+var ErrCursorExpired = errors.New("游标已过期")
+
+// Cursor 按页读取查询结果，服务端保存查询状态直到读完或调用 Close。
+// Cursor 不能并发使用。
+type Cursor struct {
+	client   *DataFusionClient
+	sql      string
+	pageSize int32
+	columns  []Column
+	// first 是打开游标时返回的第一页，尚未交给调用方
+	first []Row
+	token string
+	done  bool
+}
+
+// QueryPaged 执行查询并返回每页 pageSize 行的游标。
+func (c *DataFusionClient) QueryPaged(ctx context.Context, sql string, pageSize int) (*Cursor, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("无效的分页大小: %d", pageSize)
+	}
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := c.queryContext(ctx)
+	defer cancel()
+
+	req := &pb.OpenCursorRequest{Sql: sql, PageSize: int32(pageSize)}
+	var page *pb.Page
+	err = c.instrument(ctx, sql, func(ctx context.Context) (int, error) {
+		err := c.withRetry(ctx, isIdempotent(sql), func() error {
+			var err error
+			page, err = c.rpc.OpenCursor(ctx, req)
+			return err
+		})
+		if err != nil {
+			return 0, newQueryError(ctx, sql, err)
+		}
+		return len(page.GetRows()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{
+		client:   c,
+		sql:      sql,
+		pageSize: int32(pageSize),
+		columns:  columnsFromPB(page.GetColumns()),
+		first:    rowsFromPB(page.GetRows()),
+		token:    page.GetNextToken(),
+	}, nil
+}
+
+// Columns 返回结果集的列定义。
+func (cur *Cursor) Columns() []Column {
+	return cur.columns
+}
+
+// NextPage 返回下一页，没有更多结果时返回 io.EOF。
+// 令牌在服务端过期时返回的错误满足 errors.Is(err, ErrCursorExpired)。
+func (cur *Cursor) NextPage(ctx context.Context) ([]Row, error) {
+	if cur.first != nil {
+		rows := cur.first
+		cur.first = nil
+		return rows, nil
+	}
+	if cur.done || cur.token == "" {
+		cur.done = true
+		return nil, io.EOF
+	}
+
+	done, err := cur.client.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := cur.client.queryContext(ctx)
+	defer cancel()
+
+	var page *pb.Page
+	err = cur.client.instrument(ctx, cur.sql, func(ctx context.Context) (int, error) {
+		var err error
+		page, err = cur.client.rpc.FetchPage(ctx, &pb.FetchPageRequest{Token: cur.token, PageSize: cur.pageSize})
+		if err != nil {
+			err = newQueryError(ctx, cur.sql, err)
+			if status.Code(err) == codes.NotFound {
+				return 0, fmt.Errorf("%w: %w", ErrCursorExpired, err)
+			}
+			return 0, err
+		}
+		return len(page.GetRows()), nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrCursorExpired) {
+			cur.done = true
+		}
+		return nil, err
+	}
+	cur.token = page.GetNextToken()
+	rows := rowsFromPB(page.GetRows())
+	if len(rows) == 0 && cur.token == "" {
+		cur.done = true
+		return nil, io.EOF
+	}
+	return rows, nil
+}
+
+// Close 释放游标在服务端的状态，可以重复调用。
+func (cur *Cursor) Close(ctx context.Context) error {
+	cur.first = nil
+	if cur.done || cur.token == "" {
+		cur.done = true
+		return nil
+	}
+	cur.done = true
+	ctx = ensureRequestID(ctx)
+	if _, err := cur.client.rpc.CloseCursor(ctx, &pb.CloseCursorRequest{Token: cur.token}); err != nil {
+		return newQueryError(ctx, cur.sql, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// pagingServer 按页返回 total 行递增的整数，续页令牌是下一行的偏移量。
+// expired 中的令牌返回 NotFound
+type pagingServer struct {
+	total   int
+	expired map[string]bool
+
+	mu     sync.Mutex
+	closed []string
+}
+
+func (s *pagingServer) page(offset int, size int32) (*pb.Page, error) {
+	end := offset + int(size)
+	if end > s.total {
+		end = s.total
+	}
+	page := &pb.Page{Columns: []*pb.Column{{Name: "n"}}}
+	for i := offset; i < end; i++ {
+		page.Rows = append(page.Rows, &pb.Row{Values: []*pb.Value{mustPBValue(int64(i))}})
+	}
+	if end < s.total {
+		page.NextToken = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (s *pagingServer) fake() *fakeServer {
+	return &fakeServer{
+		openCursor: func(_ context.Context, req *pb.OpenCursorRequest) (*pb.Page, error) {
+			return s.page(0, req.GetPageSize())
+		},
+		fetchPage: func(_ context.Context, req *pb.FetchPageRequest) (*pb.Page, error) {
+			if s.expired[req.GetToken()] {
+				return nil, status.Error(codes.NotFound, "游标不存在")
+			}
+			offset, err := strconv.Atoi(req.GetToken())
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "无效的令牌")
+			}
+			return s.page(offset, req.GetPageSize())
+		},
+		closeCursor: func(_ context.Context, req *pb.CloseCursorRequest) (*pb.CloseCursorResponse, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.closed = append(s.closed, req.GetToken())
+			return &pb.CloseCursorResponse{}, nil
+		},
+	}
+}
+
+func TestQueryPagedPages(t *testing.T) {
+	srv := &pagingServer{total: 25}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	cur, err := c.QueryPaged(ctx, "SELECT n FROM numbers", 10)
+	if err != nil {
+		t.Fatalf("QueryPaged: %v", err)
+	}
+	if cols := cur.Columns(); len(cols) != 1 || cols[0].Name != "n" {
+		t.Errorf("Columns = %v, want [n]", cols)
+	}
+
+	var sizes []int
+	next := int64(0)
+	for {
+		rows, err := cur.NextPage(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPage: %v", err)
+		}
+		sizes = append(sizes, len(rows))
+		for _, row := range rows {
+			if row[0] != next {
+				t.Fatalf("第 %d 行 = %v, want %d", next, row[0], next)
+			}
+			next++
+		}
+	}
+	if len(sizes) != 3 || sizes[0] != 10 || sizes[1] != 10 || sizes[2] != 5 {
+		t.Errorf("每页行数 = %v, want [10 10 5]", sizes)
+	}
+	if _, err := cur.NextPage(ctx); err != io.EOF {
+		t.Errorf("读完后 NextPage = %v, want io.EOF", err)
+	}
+	if err := cur.Close(ctx); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if len(srv.closed) != 0 {
+		t.Errorf("读完的游标不需要 CloseCursor，服务端收到 %q", srv.closed)
+	}
+}
+
+func TestQueryPagedCloseEarly(t *testing.T) {
+	srv := &pagingServer{total: 25}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	cur, err := c.QueryPaged(ctx, "SELECT n FROM numbers", 10)
+	if err != nil {
+		t.Fatalf("QueryPaged: %v", err)
+	}
+	if _, err := cur.NextPage(ctx); err != nil {
+		t.Fatalf("NextPage: %v", err)
+	}
+	if err := cur.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := cur.Close(ctx); err != nil {
+		t.Fatalf("重复 Close: %v", err)
+	}
+	if len(srv.closed) != 1 || srv.closed[0] != "10" {
+		t.Errorf("CloseCursor 令牌 = %q, want [10]", srv.closed)
+	}
+	if _, err := cur.NextPage(ctx); err != io.EOF {
+		t.Errorf("Close 后 NextPage = %v, want io.EOF", err)
+	}
+}
+
+func TestQueryPagedExpiredToken(t *testing.T) {
+	srv := &pagingServer{total: 25, expired: map[string]bool{"10": true}}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	cur, err := c.QueryPaged(ctx, "SELECT n FROM numbers", 10)
+	if err != nil {
+		t.Fatalf("QueryPaged: %v", err)
+	}
+	if _, err := cur.NextPage(ctx); err != nil {
+		t.Fatalf("第一页: %v", err)
+	}
+	_, err = cur.NextPage(ctx)
+	if !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("令牌过期时 NextPage = %v, want ErrCursorExpired", err)
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("status.Code = %s, want NotFound", status.Code(err))
+	}
+	if _, err := cur.NextPage(ctx); err != io.EOF {
+		t.Errorf("过期后 NextPage = %v, want io.EOF", err)
+	}
+}
+
+func TestQueryPagedInvalidPageSize(t *testing.T) {
+	c := newTestClient(t, (&pagingServer{}).fake())
+	if _, err := c.QueryPaged(context.Background(), "SELECT 1", 0); err == nil {
+		t.Error("分页大小为 0 时应返回错误")
+	}
+}
@@ -0,0 +1,291 @@
+// Package datafusiontest 提供在进程内运行的 DataFusion 测试服务端，
+// 用于不依赖真实集群的端到端测试。
+package datafusiontest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/datafusion"
+	"datafusion-client/pb"
+)
+
+// Watch 轮询 Handlers.Health 的间隔
+const healthPollInterval = 50 * time.Millisecond
+
+// Handlers 定义测试服务端对各 RPC 的响应，未设置的处理函数返回 Unimplemented。
+//
+// Errors 和 Delays 以 RPC 方法名为键，如 ExecuteQuery、QueryStream、
+// ListTables、DescribeTable、Check、Watch，对应方法的调用先等待延迟再返回注入的错误，
+// 错误应使用 status.Error 构造。服务端启动后不能再修改这两个 map。
+type Handlers struct {
+	// Query 处理 ExecuteQuery
+	Query func(ctx context.Context, sql string) (*datafusion.QueryResponse, error)
+	// Stream 处理 QueryStream，返回的批次依次发送，列定义只随首个批次发送
+	Stream func(ctx context.Context, sql string) ([]*datafusion.RowBatch, error)
+	// Tables 处理 ListTables
+	Tables func(ctx context.Context) ([]datafusion.TableInfo, error)
+	// DescribeTable 处理 DescribeTable，catalog 和 schema 可能为空
+	DescribeTable func(ctx context.Context, catalog, schema, table string) (*datafusion.Schema, error)
+	// Health 返回 grpc.health.v1 报告的服务状态，为 nil 时所有服务均为 SERVING
+	Health func(service string) datafusion.HealthStatus
+
+	Errors map[string]error
+	Delays map[string]time.Duration
+}
+
+// StartServer 在随机端口上启动测试服务端，返回监听地址和停止函数。
+// 测试结束时服务端会自动停止，stop 可以重复调用。
+// 服务端不启用 TLS，客户端需要使用 datafusion.WithInsecure。
+func StartServer(t testing.TB, handlers Handlers) (addr string, stop func()) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("datafusiontest: 监听失败: %v", err)
+	}
+
+	faults := faultInjector{errors: handlers.Errors, delays: handlers.Delays}
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(faults.unary),
+		grpc.ChainStreamInterceptor(faults.stream),
+	)
+	pb.RegisterDataFusionServer(s, &server{h: handlers})
+	healthpb.RegisterHealthServer(s, &healthServer{status: handlers.Health})
+
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), s.Stop
+}
+
+// faultInjector 按方法名注入延迟和错误
+type faultInjector struct {
+	errors map[string]error
+	delays map[string]time.Duration
+}
+
+func (f faultInjector) inject(ctx context.Context, fullMethod string) error {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if d := f.delays[method]; d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return f.errors[method]
+}
+
+func (f faultInjector) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := f.inject(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (f faultInjector) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := f.inject(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+type server struct {
+	pb.UnimplementedDataFusionServer
+	h Handlers
+	// queries 用于生成 x-query-id
+	queries atomic.Int64
+}
+
+// queryIDHeader 像真实服务端一样生成携带查询 ID 的响应头。
+// 响应头在调用处理函数前立即发送，客户端在查询进行中即可取消
+func (s *server) queryIDHeader() metadata.MD {
+	return metadata.Pairs("x-query-id", fmt.Sprintf("q-%d", s.queries.Add(1)))
+}
+
+func (s *server) ExecuteQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if s.h.Query == nil {
+		return nil, status.Error(codes.Unimplemented, "datafusiontest: 未设置 Query")
+	}
+	if err := grpc.SendHeader(ctx, s.queryIDHeader()); err != nil {
+		return nil, err
+	}
+	resp, err := s.h.Query(ctx, req.GetSql())
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return &pb.QueryResponse{}, nil
+	}
+	rows, err := rowsToPB(resp.Rows)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.QueryResponse{Result: resp.Result, Columns: columnsToPB(resp.Columns), Rows: rows}, nil
+}
+
+func (s *server) QueryStream(req *pb.QueryRequest, stream pb.DataFusion_QueryStreamServer) error {
+	if s.h.Stream == nil {
+		return status.Error(codes.Unimplemented, "datafusiontest: 未设置 Stream")
+	}
+	if err := stream.SendHeader(s.queryIDHeader()); err != nil {
+		return err
+	}
+	batches, err := s.h.Stream(stream.Context(), req.GetSql())
+	if err != nil {
+		return err
+	}
+	for i, b := range batches {
+		rows, err := rowsToPB(b.Rows)
+		if err != nil {
+			return err
+		}
+		msg := &pb.RowBatch{Rows: rows}
+		if i == 0 {
+			msg.Columns = columnsToPB(b.Columns)
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) ListTables(ctx context.Context, _ *pb.ListTablesRequest) (*pb.ListTablesResponse, error) {
+	if s.h.Tables == nil {
+		return nil, status.Error(codes.Unimplemented, "datafusiontest: 未设置 Tables")
+	}
+	tables, err := s.h.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListTablesResponse{}
+	for _, t := range tables {
+		resp.Tables = append(resp.Tables, tableInfoToPB(t))
+	}
+	return resp, nil
+}
+
+func (s *server) DescribeTable(ctx context.Context, req *pb.DescribeTableRequest) (*pb.DescribeTableResponse, error) {
+	if s.h.DescribeTable == nil {
+		return nil, status.Error(codes.Unimplemented, "datafusiontest: 未设置 DescribeTable")
+	}
+	schema, err := s.h.DescribeTable(ctx, req.GetCatalog(), req.GetSchema(), req.GetTable())
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, status.Errorf(codes.NotFound, "表 %s 不存在", req.GetTable())
+	}
+	return &pb.DescribeTableResponse{Table: tableInfoToPB(schema.Table), Columns: columnsToPB(schema.Columns)}, nil
+}
+
+// healthServer 按 Handlers.Health 报告状态
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	status func(service string) datafusion.HealthStatus
+}
+
+func (h *healthServer) current(service string) healthpb.HealthCheckResponse_ServingStatus {
+	if h.status == nil {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	switch h.status(service) {
+	case datafusion.HealthServing:
+		return healthpb.HealthCheckResponse_SERVING
+	case datafusion.HealthNotServing:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	default:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+}
+
+func (h *healthServer) Check(_ context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return &healthpb.HealthCheckResponse{Status: h.current(req.GetService())}, nil
+}
+
+// Watch 先发送当前状态，之后轮询 Handlers.Health 并在状态变化时发送
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	last := h.current(req.GetService())
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: last}); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+		if s := h.current(req.GetService()); s != last {
+			last = s
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: s}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func columnsToPB(cols []datafusion.Column) []*pb.Column {
+	out := make([]*pb.Column, len(cols))
+	for i, c := range cols {
+		out[i] = &pb.Column{Name: c.Name, DataType: c.DataType, Nullable: c.Nullable}
+	}
+	return out
+}
+
+func rowsToPB(rows []datafusion.Row) ([]*pb.Row, error) {
+	out := make([]*pb.Row, len(rows))
+	for i, row := range rows {
+		values := make([]*pb.Value, len(row))
+		for j, v := range row {
+			pv, err := valueToPB(v)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "datafusiontest: 第 %d 行第 %d 列: %v", i+1, j+1, err)
+			}
+			values[j] = pv
+		}
+		out[i] = &pb.Row{Values: values}
+	}
+	return out, nil
+}
+
+func valueToPB(v any) (*pb.Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return &pb.Value{}, nil
+	case bool:
+		return &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: x}}, nil
+	case int:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(x)}}, nil
+	case int64:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: x}}, nil
+	case float64:
+		return &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: x}}, nil
+	case string:
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: x}}, nil
+	case []byte:
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: x}}, nil
+	case time.Time:
+		return &pb.Value{Kind: &pb.Value_TimestampMicros{TimestampMicros: x.UnixMicro()}}, nil
+	default:
+		return nil, fmt.Errorf("不支持的值类型 %T", v)
+	}
+}
+
+func tableInfoToPB(t datafusion.TableInfo) *pb.TableInfo {
+	return &pb.TableInfo{Catalog: t.Catalog, Schema: t.Schema, Name: t.Name, TableType: t.Type}
+}
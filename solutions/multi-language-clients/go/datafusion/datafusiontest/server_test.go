@@ -0,0 +1,299 @@
+package datafusiontest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/datafusion"
+	"datafusion-client/pb"
+)
+
+// newClient 连接测试服务端，测试结束时关闭
+func newClient(t *testing.T, handlers Handlers) *datafusion.DataFusionClient {
+	t.Helper()
+	addr, _ := StartServer(t, handlers)
+	c, err := datafusion.NewClient(context.Background(), addr, datafusion.WithInsecure())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+var usersColumns = []datafusion.Column{
+	{Name: "id", DataType: "Int64"},
+	{Name: "name", DataType: "Utf8", Nullable: true},
+}
+
+func TestQuery(t *testing.T) {
+	c := newClient(t, Handlers{
+		Query: func(_ context.Context, sql string) (*datafusion.QueryResponse, error) {
+			if sql != "SELECT id, name FROM users" {
+				return nil, status.Errorf(codes.InvalidArgument, "意外的 SQL: %s", sql)
+			}
+			return &datafusion.QueryResponse{
+				Columns: usersColumns,
+				Rows:    []datafusion.Row{{int64(1), "alice"}, {2, nil}},
+			}, nil
+		},
+	})
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if resp.QueryID != "q-1" {
+		t.Errorf("QueryID = %q, want q-1", resp.QueryID)
+	}
+	if len(resp.Columns) != 2 || resp.Columns[1] != usersColumns[1] {
+		t.Errorf("Columns = %+v", resp.Columns)
+	}
+	if len(resp.Rows) != 2 || resp.Rows[0][1] != "alice" || resp.Rows[1][0] != int64(2) || resp.Rows[1][1] != nil {
+		t.Errorf("Rows = %v", resp.Rows)
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := newClient(t, Handlers{
+		Stream: func(context.Context, string) ([]*datafusion.RowBatch, error) {
+			return []*datafusion.RowBatch{
+				{Columns: usersColumns, Rows: []datafusion.Row{{int64(1), "alice"}}},
+				{Rows: []datafusion.Row{{int64(2), "bob"}, {int64(3), nil}}},
+			}, nil
+		},
+	})
+
+	s, err := c.ExecuteQueryStream(context.Background(), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	defer s.Close()
+	var rows int
+	for {
+		b, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if len(b.Columns) != 2 {
+			t.Errorf("批次的 Columns = %+v, want 沿用首个批次的列定义", b.Columns)
+		}
+		rows += len(b.Rows)
+	}
+	if rows != 3 {
+		t.Errorf("共收到 %d 行, want 3", rows)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	c := newClient(t, Handlers{
+		Query: func(context.Context, string) (*datafusion.QueryResponse, error) {
+			return nil, status.Error(codes.InvalidArgument, "表 missing 不存在")
+		},
+	})
+	_, err := c.ExecuteQuery(context.Background(), "SELECT * FROM missing")
+	var qe *datafusion.QueryError
+	if !errors.As(err, &qe) || qe.Code != codes.InvalidArgument || qe.Message != "表 missing 不存在" {
+		t.Errorf("err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestUnsetHandler(t *testing.T) {
+	c := newClient(t, Handlers{})
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ExecuteQuery err = %v, want Unimplemented", err)
+	}
+	if _, err := c.ListTables(ctx); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ListTables err = %v, want Unimplemented", err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	users := datafusion.TableInfo{Catalog: "datafusion", Schema: "public", Name: "users", Type: "BASE TABLE"}
+	c := newClient(t, Handlers{
+		Tables: func(context.Context) ([]datafusion.TableInfo, error) {
+			return []datafusion.TableInfo{users}, nil
+		},
+		DescribeTable: func(_ context.Context, catalog, schema, table string) (*datafusion.Schema, error) {
+			if table != "users" {
+				return nil, nil
+			}
+			if catalog != "" || schema != "public" {
+				t.Errorf("DescribeTable(%q, %q, %q)", catalog, schema, table)
+			}
+			return &datafusion.Schema{Table: users, Columns: usersColumns}, nil
+		},
+	})
+	ctx := context.Background()
+
+	tables, err := c.ListTables(ctx)
+	if err != nil || len(tables) != 1 || tables[0] != users {
+		t.Errorf("ListTables = %+v, %v", tables, err)
+	}
+	schema, err := c.DescribeTable(ctx, "public.users")
+	if err != nil || schema.Table != users || len(schema.Columns) != 2 {
+		t.Errorf("DescribeTable = %+v, %v", schema, err)
+	}
+	if _, err := c.DescribeTable(ctx, "public.missing"); status.Code(err) != codes.NotFound {
+		t.Errorf("DescribeTable(missing) err = %v, want NotFound", err)
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	c := newClient(t, Handlers{
+		Query: func(context.Context, string) (*datafusion.QueryResponse, error) {
+			t.Error("注入错误时不应调用处理函数")
+			return nil, nil
+		},
+		Tables: func(context.Context) ([]datafusion.TableInfo, error) { return nil, nil },
+		Errors: map[string]error{"ExecuteQuery": status.Error(codes.ResourceExhausted, "内存不足")},
+	})
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("ExecuteQuery err = %v, want ResourceExhausted", err)
+	}
+	// 其他方法不受影响
+	if _, err := c.ListTables(ctx); err != nil {
+		t.Errorf("ListTables: %v", err)
+	}
+}
+
+func TestDelays(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	c := newClient(t, Handlers{
+		Query: func(context.Context, string) (*datafusion.QueryResponse, error) {
+			return &datafusion.QueryResponse{Result: "ok"}, nil
+		},
+		Delays: map[string]time.Duration{"ExecuteQuery": delay},
+	})
+
+	start := time.Now()
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("耗时 %v, want 至少 %v", elapsed, delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), delay/4)
+	defer cancel()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("超时 err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	next := make(chan datafusion.HealthStatus, 1)
+	next <- datafusion.HealthServing
+	current := datafusion.HealthServing
+	c := newClient(t, Handlers{
+		Health: func(service string) datafusion.HealthStatus {
+			if service == "unknown" {
+				return datafusion.HealthUnknown
+			}
+			select {
+			case current = <-next:
+			default:
+			}
+			return current
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if got, err := c.HealthCheck(ctx, "unknown"); err != nil || got != datafusion.HealthUnknown {
+		t.Errorf("HealthCheck(unknown) = %v, %v", got, err)
+	}
+	updates, err := c.WatchHealth(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchHealth: %v", err)
+	}
+	if got := <-updates; got != datafusion.HealthServing {
+		t.Errorf("首个状态 = %v, want SERVING", got)
+	}
+	next <- datafusion.HealthNotServing
+	select {
+	case got := <-updates:
+		if got != datafusion.HealthNotServing {
+			t.Errorf("变化后的状态 = %v, want NOT_SERVING", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到状态变化")
+	}
+}
+
+// headerRecorder 在响应头到达时记录其中的查询 ID
+type headerRecorder struct {
+	ids chan string
+}
+
+func (headerRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context   { return ctx }
+func (headerRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+func (headerRecorder) HandleConn(context.Context, stats.ConnStats)                       {}
+
+func (h headerRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if in, ok := s.(*stats.InHeader); ok && in.IsClient() {
+		if ids := in.Header.Get("x-query-id"); len(ids) > 0 {
+			h.ids <- ids[0]
+		}
+	}
+}
+
+func TestQueryIDSentBeforeResponse(t *testing.T) {
+	release := make(chan struct{})
+	addr, _ := StartServer(t, Handlers{
+		Query: func(context.Context, string) (*datafusion.QueryResponse, error) {
+			<-release
+			return &datafusion.QueryResponse{}, nil
+		},
+		Stream: func(context.Context, string) ([]*datafusion.RowBatch, error) {
+			<-release
+			return nil, nil
+		},
+	})
+	defer close(release)
+
+	rec := headerRecorder{ids: make(chan string, 2)}
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStatsHandler(rec))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	rpc := pb.NewDataFusionClient(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go rpc.ExecuteQuery(ctx, &pb.QueryRequest{Sql: "SELECT slow"})
+	select {
+	case id := <-rec.ids:
+		if id != "q-1" {
+			t.Errorf("查询 ID = %q, want q-1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("处理函数返回前未收到查询 ID")
+	}
+
+	stream, err := rpc.QueryStream(ctx, &pb.QueryRequest{Sql: "SELECT slow"})
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	md, err := stream.Header()
+	if err != nil {
+		t.Fatalf("Header: %v", err)
+	}
+	if got := md.Get("x-query-id"); len(got) != 1 || got[0] != "q-2" {
+		t.Errorf("流的查询 ID = %v, want q-2", got)
+	}
+}
@@ -0,0 +1,224 @@
+package datafusion
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CompareOption 配置 CompareResults。
+type CompareOption func(*compareOptions)
+
+type compareOptions struct {
+	ignoreOrder bool
+}
+
+// IgnoreRowOrder 按集合比较行：行的顺序不同不算差异，重复行按出现次数比较。
+// 默认按顺序逐行比较。
+func IgnoreRowOrder() CompareOption {
+	return func(o *compareOptions) {
+		o.ignoreOrder = true
+	}
+}
+
+// ColumnChange 是同名列的类型变化。
+type ColumnChange struct {
+	Name string
+	From string
+	To   string
+}
+
+// RowChange 是按顺序比较时同一位置上内容不同的行。
+type RowChange struct {
+	// Index 是从 0 开始的行号
+	Index int
+	From  Row
+	To    Row
+}
+
+// Diff 是两个结果集的差异，a 为基准，b 为比较对象。
+// 行只按两边共有的列比较，列的顺序以 a 为准。
+type Diff struct {
+	// AddedColumns 是只在 b 中出现的列
+	AddedColumns []Column
+	// RemovedColumns 是只在 a 中出现的列
+	RemovedColumns []Column
+	RetypedColumns []ColumnChange
+
+	// ChangedRows 只在按顺序比较时出现
+	ChangedRows []RowChange
+	// AddedRows 是只在 b 中出现的行
+	AddedRows []Row
+	// RemovedRows 是只在 a 中出现的行
+	RemovedRows []Row
+}
+
+// Equal 报告两个结果集是否没有差异。
+func (d *Diff) Equal() bool {
+	return len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 && len(d.RetypedColumns) == 0 &&
+		len(d.ChangedRows) == 0 && len(d.AddedRows) == 0 && len(d.RemovedRows) == 0
+}
+
+// String 逐行列出差异，没有差异时返回 "结果一致"。
+func (d *Diff) String() string {
+	if d.Equal() {
+		return "结果一致"
+	}
+	var b strings.Builder
+	for _, col := range d.AddedColumns {
+		fmt.Fprintf(&b, "+ 列 %s (%s)\n", col.Name, col.DataType)
+	}
+	for _, col := range d.RemovedColumns {
+		fmt.Fprintf(&b, "- 列 %s (%s)\n", col.Name, col.DataType)
+	}
+	for _, ch := range d.RetypedColumns {
+		fmt.Fprintf(&b, "~ 列 %s: %s -> %s\n", ch.Name, ch.From, ch.To)
+	}
+	for _, ch := range d.ChangedRows {
+		fmt.Fprintf(&b, "~ 第 %d 行: %s -> %s\n", ch.Index+1, formatRow(ch.From), formatRow(ch.To))
+	}
+	for _, row := range d.AddedRows {
+		fmt.Fprintf(&b, "+ %s\n", formatRow(row))
+	}
+	for _, row := range d.RemovedRows {
+		fmt.Fprintf(&b, "- %s\n", formatRow(row))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// CompareResults 比较两个结果集的列定义和行，适用于查询迁移的回归测试。
+// 列按名称匹配；行默认按顺序比较，传入 IgnoreRowOrder 时按集合比较。
+// 差异中的行只包含两边共有的列。
+func CompareResults(a, b *QueryResponse, opts ...CompareOption) (*Diff, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("比较的结果集不能为空")
+	}
+	var o compareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	aIdx, err := columnIndex(a.Columns)
+	if err != nil {
+		return nil, err
+	}
+	bIdx, err := columnIndex(b.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diff{}
+	// 共有列在 a、b 中的位置
+	var aPos, bPos []int
+	for i, col := range a.Columns {
+		j, ok := bIdx[col.Name]
+		if !ok {
+			d.RemovedColumns = append(d.RemovedColumns, col)
+			continue
+		}
+		if other := b.Columns[j]; other.DataType != col.DataType {
+			d.RetypedColumns = append(d.RetypedColumns, ColumnChange{Name: col.Name, From: col.DataType, To: other.DataType})
+		}
+		aPos = append(aPos, i)
+		bPos = append(bPos, j)
+	}
+	for _, col := range b.Columns {
+		if _, ok := aIdx[col.Name]; !ok {
+			d.AddedColumns = append(d.AddedColumns, col)
+		}
+	}
+
+	aRows := projectRows(a.Rows, aPos)
+	bRows := projectRows(b.Rows, bPos)
+	if o.ignoreOrder {
+		compareRowSets(d, aRows, bRows)
+	} else {
+		compareRowsOrdered(d, aRows, bRows)
+	}
+	return d, nil
+}
+
+func columnIndex(cols []Column) (map[string]int, error) {
+	idx := make(map[string]int, len(cols))
+	for i, col := range cols {
+		if _, ok := idx[col.Name]; ok {
+			return nil, fmt.Errorf("结果集包含重复的列名: %s", col.Name)
+		}
+		idx[col.Name] = i
+	}
+	return idx, nil
+}
+
+// projectRows 只保留 pos 指定的列，缺失的值视为 NULL
+func projectRows(rows []Row, pos []int) []Row {
+	out := make([]Row, len(rows))
+	for i, row := range rows {
+		r := make(Row, len(pos))
+		for j, p := range pos {
+			if p < len(row) {
+				r[j] = row[p]
+			}
+		}
+		out[i] = r
+	}
+	return out
+}
+
+func compareRowsOrdered(d *Diff, a, b []Row) {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if rowKey(a[i]) != rowKey(b[i]) {
+			d.ChangedRows = append(d.ChangedRows, RowChange{Index: i, From: a[i], To: b[i]})
+		}
+	}
+	d.RemovedRows = append(d.RemovedRows, a[n:]...)
+	d.AddedRows = append(d.AddedRows, b[n:]...)
+}
+
+// compareRowSets 按多重集合比较，结果中的行保持各自原来的顺序
+func compareRowSets(d *Diff, a, b []Row) {
+	counts := make(map[string]int, len(b))
+	for _, row := range b {
+		counts[rowKey(row)]++
+	}
+	for _, row := range a {
+		k := rowKey(row)
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		d.RemovedRows = append(d.RemovedRows, row)
+	}
+	for _, row := range b {
+		k := rowKey(row)
+		if counts[k] > 0 {
+			counts[k]--
+			d.AddedRows = append(d.AddedRows, row)
+		}
+	}
+}
+
+// rowKey 将一行编码为可比较的键，值的类型不同即视为不同
+func rowKey(row Row) string {
+	var b strings.Builder
+	for _, v := range row {
+		if v == nil {
+			b.WriteString("N;")
+			continue
+		}
+		s := formatValue(v)
+		fmt.Fprintf(&b, "%T:%d:%s;", v, len(s), s)
+	}
+	return b.String()
+}
+
+func formatRow(row Row) string {
+	cells := make([]string, len(row))
+	for i, v := range row {
+		cells[i] = "NULL"
+		if v != nil {
+			cells[i] = formatValue(v)
+		}
+	}
+	return "(" + strings.Join(cells, ", ") + ")"
+}
@@ -0,0 +1,108 @@
+package datafusion
+
+import (
+	"testing"
+)
+
+func diffFixture(rows ...Row) *QueryResponse {
+	return &QueryResponse{
+		Columns: []Column{{Name: "id", DataType: "Int64"}, {Name: "name", DataType: "Utf8"}},
+		Rows:    rows,
+	}
+}
+
+func TestCompareResultsReorderedRows(t *testing.T) {
+	a := diffFixture(Row{int64(1), "a"}, Row{int64(2), "b"}, Row{int64(2), "b"})
+	b := diffFixture(Row{int64(2), "b"}, Row{int64(1), "a"}, Row{int64(2), "b"})
+
+	set, err := CompareResults(a, b, IgnoreRowOrder())
+	if err != nil {
+		t.Fatalf("CompareResults: %v", err)
+	}
+	if !set.Equal() {
+		t.Errorf("按集合比较时顺序不同不应有差异:\n%s", set)
+	}
+
+	ordered, err := CompareResults(a, b)
+	if err != nil {
+		t.Fatalf("CompareResults: %v", err)
+	}
+	if ordered.Equal() {
+		t.Fatal("按顺序比较时顺序不同应有差异")
+	}
+	if len(ordered.ChangedRows) != 2 || ordered.ChangedRows[0].Index != 0 || ordered.ChangedRows[1].Index != 1 {
+		t.Errorf("ChangedRows = %+v", ordered.ChangedRows)
+	}
+	want := "~ 第 1 行: (1, a) -> (2, b)\n~ 第 2 行: (2, b) -> (1, a)"
+	if got := ordered.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCompareResultsDuplicateCounts(t *testing.T) {
+	a := diffFixture(Row{int64(1), "a"}, Row{int64(1), "a"})
+	b := diffFixture(Row{int64(1), "a"}, Row{int64(3), "c"})
+
+	d, err := CompareResults(a, b, IgnoreRowOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.RemovedRows) != 1 || len(d.AddedRows) != 1 {
+		t.Fatalf("差异 = %s", d)
+	}
+	if d.AddedRows[0][0] != int64(3) {
+		t.Errorf("AddedRows = %v", d.AddedRows)
+	}
+}
+
+func TestCompareResultsColumnTypeChange(t *testing.T) {
+	a := diffFixture(Row{int64(1), "a"})
+	b := &QueryResponse{
+		Columns: []Column{{Name: "id", DataType: "Utf8"}, {Name: "name", DataType: "Utf8"}, {Name: "age", DataType: "Int32"}},
+		Rows:    []Row{{"1", "a", int64(30)}},
+	}
+
+	d, err := CompareResults(a, b, IgnoreRowOrder())
+	if err != nil {
+		t.Fatalf("CompareResults: %v", err)
+	}
+	if len(d.RetypedColumns) != 1 || d.RetypedColumns[0] != (ColumnChange{Name: "id", From: "Int64", To: "Utf8"}) {
+		t.Errorf("RetypedColumns = %+v", d.RetypedColumns)
+	}
+	if len(d.AddedColumns) != 1 || d.AddedColumns[0].Name != "age" {
+		t.Errorf("AddedColumns = %+v", d.AddedColumns)
+	}
+	// 值的类型随列一起变化，int64(1) 与 "1" 不相等
+	if len(d.RemovedRows) != 1 || len(d.AddedRows) != 1 {
+		t.Errorf("类型变化后的行应视为不同:\n%s", d)
+	}
+	if len(d.AddedRows) == 1 && len(d.AddedRows[0]) != 2 {
+		t.Errorf("差异中的行应只包含共有列: %v", d.AddedRows[0])
+	}
+}
+
+func TestCompareResultsNullsAndMissingValues(t *testing.T) {
+	a := diffFixture(Row{int64(1), nil})
+	b := diffFixture(Row{int64(1)})
+
+	d, err := CompareResults(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal() {
+		t.Errorf("缺失的值应视为 NULL:\n%s", d)
+	}
+	if d.String() != "结果一致" {
+		t.Errorf("String() = %q", d.String())
+	}
+}
+
+func TestCompareResultsErrors(t *testing.T) {
+	if _, err := CompareResults(nil, diffFixture()); err == nil {
+		t.Error("空结果集应返回错误")
+	}
+	dup := &QueryResponse{Columns: []Column{{Name: "a"}, {Name: "a"}}}
+	if _, err := CompareResults(dup, diffFixture()); err == nil {
+		t.Error("重复的列名应返回错误")
+	}
+}
@@ -0,0 +1,108 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReasonSyntaxError 是服务端在 ErrorInfo.Reason 中标记 SQL 语法错误的取值。
+const ReasonSyntaxError = "SYNTAX_ERROR"
+
+// QueryError 是查询失败时返回的错误，保留了 gRPC 状态中的信息。
+// 可通过 errors.As 获取。
+type QueryError struct {
+	// Code 是 gRPC 状态码
+	Code codes.Code
+	// Message 是服务端返回的错误信息
+	Message string
+	// SQL 是出错的查询
+	SQL string
+	// Details 是状态中附带的 google.rpc.ErrorInfo
+	Details []*errdetails.ErrorInfo
+	// RequestID 是失败调用发送的 x-request-id
+	RequestID string
+
+	err error
+}
+
+func (e *QueryError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("查询失败 (%s): %s [请求 ID %s]", e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("查询失败 (%s): %s", e.Code, e.Message)
+}
+
+// Unwrap 返回原始错误。
+func (e *QueryError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus 使 status.Code 等函数可以直接作用于 QueryError。
+func (e *QueryError) GRPCStatus() *status.Status {
+	if st, ok := status.FromError(e.err); ok {
+		return st
+	}
+	return status.New(e.Code, e.Message)
+}
+
+// newQueryError 将 RPC 错误包装为 *QueryError，err 为 nil 时返回 nil
+func newQueryError(ctx context.Context, sql string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var qe *QueryError
+	if errors.As(err, &qe) {
+		return err
+	}
+
+	qe = &QueryError{SQL: sql, RequestID: requestID(ctx), err: err}
+	st, ok := status.FromError(err)
+	if !ok {
+		qe.Code = status.FromContextError(err).Code()
+		qe.Message = err.Error()
+		return qe
+	}
+	qe.Code = st.Code()
+	qe.Message = st.Message()
+	if qe.Code == codes.ResourceExhausted && strings.Contains(qe.Message, "larger than max") {
+		qe.Message += " (可通过 WithMaxRecvMsgSize 或 WithMaxSendMsgSize 调整限制)"
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			qe.Details = append(qe.Details, info)
+		}
+	}
+	return qe
+}
+
+// IsSyntaxError 判断错误是否由 SQL 语法错误引起。
+func IsSyntaxError(err error) bool {
+	var qe *QueryError
+	if !errors.As(err, &qe) {
+		return false
+	}
+	for _, info := range qe.Details {
+		if info.GetReason() == ReasonSyntaxError {
+			return true
+		}
+	}
+	if qe.Code != codes.InvalidArgument {
+		return false
+	}
+	msg := strings.ToLower(qe.Message)
+	return strings.Contains(msg, "syntax") || strings.Contains(msg, "parsererror")
+}
+
+// IsTimeout 判断错误是否由超时引起。
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return status.Code(err) == codes.DeadlineExceeded
+}
@@ -0,0 +1,114 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// statusWithInfo 返回附带 ErrorInfo 的 gRPC 状态错误
+func statusWithInfo(t *testing.T, code codes.Code, msg, reason string) error {
+	t.Helper()
+	st, err := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   "datafusion",
+		Metadata: map[string]string{"line": "1"},
+	})
+	if err != nil {
+		t.Fatalf("构造状态失败: %v", err)
+	}
+	return st.Err()
+}
+
+func TestQueryErrorDetailsFromServer(t *testing.T) {
+	srv := &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return nil, statusWithInfo(t, codes.InvalidArgument, "unexpected token", ReasonSyntaxError)
+		},
+	}
+	c := newTestClient(t, srv)
+
+	_, err := c.ExecuteQuery(context.Background(), "SELEC 1")
+	var qe *QueryError
+	if !errors.As(err, &qe) {
+		t.Fatalf("错误 %v (%T) 不是 *QueryError", err, err)
+	}
+	if qe.Code != codes.InvalidArgument || qe.Message != "unexpected token" || qe.SQL != "SELEC 1" {
+		t.Errorf("QueryError = {%s %q %q}, want {InvalidArgument \"unexpected token\" \"SELEC 1\"}", qe.Code, qe.Message, qe.SQL)
+	}
+	if len(qe.Details) != 1 {
+		t.Fatalf("Details = %v, want 1 个 ErrorInfo", qe.Details)
+	}
+	info := qe.Details[0]
+	if info.GetReason() != ReasonSyntaxError || info.GetDomain() != "datafusion" || info.GetMetadata()["line"] != "1" {
+		t.Errorf("ErrorInfo = %v", info)
+	}
+	if qe.RequestID == "" {
+		t.Error("QueryError 应记录请求 ID")
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("status.Code = %s, want InvalidArgument", status.Code(err))
+	}
+	if !IsSyntaxError(err) {
+		t.Error("IsSyntaxError = false, want true")
+	}
+}
+
+func TestIsSyntaxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"ErrorInfo 标记", statusWithInfo(t, codes.Internal, "解析失败", ReasonSyntaxError), true},
+		{"其他 reason", statusWithInfo(t, codes.InvalidArgument, "表不存在", "TABLE_NOT_FOUND"), false},
+		{"消息含 syntax", status.Error(codes.InvalidArgument, "SQL syntax error near FROM"), true},
+		{"消息含 ParserError", status.Error(codes.InvalidArgument, "ParserError: Expected an expression"), true},
+		{"非 InvalidArgument", status.Error(codes.Internal, "syntax error"), false},
+		{"不是查询错误", errors.New("syntax error"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newQueryError(context.Background(), "SELECT", tt.err)
+			if got := IsSyntaxError(err); got != tt.want {
+				t.Errorf("IsSyntaxError(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueryErrorWrapsOnce(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	inner := newQueryError(ctx, "SELECT 1", status.Error(codes.Unavailable, "down"))
+	wrapped := fmt.Errorf("重试失败: %w", inner)
+	if got := newQueryError(ctx, "SELECT 1", wrapped); got != wrapped {
+		t.Errorf("已包含 *QueryError 的错误不应再次包装: %v", got)
+	}
+
+	var qe *QueryError
+	if !errors.As(inner, &qe) || qe.RequestID != "req-1" {
+		t.Fatalf("QueryError.RequestID = %q, want req-1", qe.RequestID)
+	}
+	if want := "查询失败 (Unavailable): down [请求 ID req-1]"; inner.Error() != want {
+		t.Errorf("Error() = %q, want %q", inner.Error(), want)
+	}
+}
+
+func TestNewQueryErrorContextError(t *testing.T) {
+	err := newQueryError(context.Background(), "SELECT 1", context.DeadlineExceeded)
+	var qe *QueryError
+	if !errors.As(err, &qe) || qe.Code != codes.DeadlineExceeded {
+		t.Fatalf("err = %v, want Code DeadlineExceeded", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !IsTimeout(err) {
+		t.Error("QueryError 应能 Unwrap 到 context.DeadlineExceeded")
+	}
+}
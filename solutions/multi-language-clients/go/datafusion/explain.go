@@ -0,0 +1,229 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EXPLAIN 结果中物理计划所在行的 plan_type
+var physicalPlanTypes = []string{"Plan with Metrics", "physical_plan"}
+
+// QueryPlan 是 EXPLAIN 返回的物理执行计划。
+type QueryPlan struct {
+	// Root 是计划树的根节点
+	Root *PlanNode
+	// Text 是服务端返回的原始计划文本
+	Text string
+}
+
+// PlanNode 是执行计划中的一个算子。
+type PlanNode struct {
+	// Type 是算子名，如 HashJoinExec、ProjectionExec
+	Type string
+	// Details 是算子名之后的描述文本
+	Details string
+	// EstimatedRows 是优化器估计的输出行数，-1 表示未知
+	EstimatedRows int64
+	// Metrics 是 EXPLAIN ANALYZE 得到的运行指标，如 output_rows、elapsed_compute
+	Metrics  map[string]string
+	Children []*PlanNode
+}
+
+// Explain 返回 sql 的物理执行计划。analyze 为 true 时执行 EXPLAIN ANALYZE，
+// 语句会真正执行一次，节点带有实际运行指标。
+// 服务端只返回文本时，整个计划作为单个节点返回。
+func (c *DataFusionClient) Explain(ctx context.Context, sql string, analyze bool) (*QueryPlan, error) {
+	stmt := "EXPLAIN " + sql
+	if analyze {
+		stmt = "EXPLAIN ANALYZE " + sql
+	}
+	resp, err := c.ExecuteQuery(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return planFromResponse(resp)
+}
+
+// planFromResponse 从 EXPLAIN 结果 (plan_type, plan 两列) 中取出物理计划
+func planFromResponse(resp *QueryResponse) (*QueryPlan, error) {
+	typeCol, planCol := -1, -1
+	for i, col := range resp.Columns {
+		switch col.Name {
+		case "plan_type":
+			typeCol = i
+		case "plan":
+			planCol = i
+		}
+	}
+	if typeCol >= 0 && planCol >= 0 {
+		for i, row := range resp.Rows {
+			if len(row) <= max(typeCol, planCol) {
+				return nil, fmt.Errorf("EXPLAIN 结果第 %d 行只有 %d 列", i+1, len(row))
+			}
+		}
+		for _, want := range physicalPlanTypes {
+			for _, row := range resp.Rows {
+				if planType, _ := row[typeCol].(string); planType == want {
+					text, _ := row[planCol].(string)
+					return ParsePlan(text), nil
+				}
+			}
+		}
+	}
+
+	text := strings.TrimSpace(resp.Result)
+	return &QueryPlan{Root: &PlanNode{Details: text, EstimatedRows: -1}, Text: text}, nil
+}
+
+// ParsePlan 解析 DataFusion 的缩进式计划文本，子节点比父节点缩进更深。
+func ParsePlan(text string) *QueryPlan {
+	type frame struct {
+		indent int
+		node   *PlanNode
+	}
+	var (
+		root  *PlanNode
+		stack []frame
+	)
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		node := parsePlanNode(strings.TrimRight(trimmed, " \r"))
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		switch {
+		case len(stack) > 0:
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, node)
+		case root == nil:
+			root = node
+		default:
+			// 多个顶层节点时挂在第一个节点下，保证得到一棵树
+			root.Children = append(root.Children, node)
+		}
+		stack = append(stack, frame{indent: indent, node: node})
+	}
+	if root == nil {
+		root = &PlanNode{EstimatedRows: -1}
+	}
+	return &QueryPlan{Root: root, Text: text}
+}
+
+// parsePlanNode 解析形如 "HashJoinExec: mode=Partitioned, metrics=[output_rows=5]" 的一行
+func parsePlanNode(line string) *PlanNode {
+	node := &PlanNode{EstimatedRows: -1}
+	name, details, _ := strings.Cut(line, ":")
+	if strings.ContainsAny(name, " =") {
+		node.Details = line
+		return node
+	}
+	node.Type = name
+	node.Details = strings.TrimSpace(details)
+
+	if metrics, ok := bracketValue(node.Details, "metrics="); ok && metrics != "" {
+		node.Metrics = make(map[string]string)
+		for _, kv := range splitTopLevel(metrics) {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				node.Metrics[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	if stats, ok := bracketValue(node.Details, "statistics="); ok {
+		for _, kv := range splitTopLevel(stats) {
+			if k, v, ok := strings.Cut(kv, "="); ok && strings.TrimSpace(k) == "Rows" {
+				node.EstimatedRows = parseStatValue(v)
+			}
+		}
+	}
+	return node
+}
+
+// bracketValue 返回 key 之后方括号内的内容，支持嵌套括号
+func bracketValue(s, key string) (string, bool) {
+	i := strings.Index(s, key+"[")
+	if i < 0 {
+		return "", false
+	}
+	start := i + len(key) + 1
+	depth := 1
+	for j := start; j < len(s); j++ {
+		switch s[j] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth == 0 {
+				return s[start:j], true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitTopLevel 按不在括号内的逗号切分
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// parseStatValue 解析 Exact(5)、Inexact(5) 或 5 形式的统计值，无法识别时返回 -1
+func parseStatValue(v string) int64 {
+	v = strings.TrimSpace(v)
+	if open := strings.IndexByte(v, '('); open >= 0 && strings.HasSuffix(v, ")") {
+		v = v[open+1 : len(v)-1]
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// String 以缩进形式输出计划树，每层缩进两个空格。
+func (p *QueryPlan) String() string {
+	var b strings.Builder
+	if p.Root != nil {
+		p.Root.write(&b, 0)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (n *PlanNode) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	switch {
+	case n.Type == "":
+		b.WriteString(n.Details)
+	case n.Details == "":
+		b.WriteString(n.Type)
+	default:
+		fmt.Fprintf(b, "%s: %s", n.Type, n.Details)
+	}
+	b.WriteByte('\n')
+	for _, child := range n.Children {
+		child.write(b, depth+1)
+	}
+}
@@ -0,0 +1,94 @@
+package datafusion
+
+import (
+	"strings"
+	"testing"
+)
+
+const nestedJoinPlan = `ProjectionExec: expr=[a@0 as a, d@3 as d]
+  HashJoinExec: mode=Partitioned, join_type=Inner, on=[(b@1, c@0)], metrics=[output_rows=7, elapsed_compute=1.2ms]
+    HashJoinExec: mode=CollectLeft, join_type=Inner, on=[(a@0, e@0)], statistics=[Rows=Exact(40), Bytes=Absent]
+      MemoryExec: partitions=1, partition_sizes=[1], statistics=[Rows=Exact(10)]
+      MemoryExec: partitions=1, partition_sizes=[1], statistics=[Rows=Inexact(4)]
+    HashJoinExec: mode=CollectLeft, join_type=Left, on=[(c@0, f@0)]
+      CsvExec: file_groups={1 group: [[t.csv]]}, projection=[c, d]
+      MemoryExec: partitions=1, partition_sizes=[2]`
+
+func TestParsePlanNestedJoins(t *testing.T) {
+	plan := ParsePlan(nestedJoinPlan)
+
+	root := plan.Root
+	if root.Type != "ProjectionExec" || len(root.Children) != 1 {
+		t.Fatalf("根节点 = %s, %d 个子节点", root.Type, len(root.Children))
+	}
+	join := root.Children[0]
+	if join.Type != "HashJoinExec" || len(join.Children) != 2 {
+		t.Fatalf("连接节点 = %s, %d 个子节点, want HashJoinExec 带 2 个子节点", join.Type, len(join.Children))
+	}
+	if join.Metrics["output_rows"] != "7" || join.Metrics["elapsed_compute"] != "1.2ms" {
+		t.Errorf("Metrics = %v", join.Metrics)
+	}
+
+	left, right := join.Children[0], join.Children[1]
+	for _, child := range join.Children {
+		if child.Type != "HashJoinExec" || len(child.Children) != 2 {
+			t.Fatalf("子连接 = %s, %d 个子节点", child.Type, len(child.Children))
+		}
+	}
+	if left.EstimatedRows != 40 {
+		t.Errorf("左连接 EstimatedRows = %d, want 40", left.EstimatedRows)
+	}
+	if right.EstimatedRows != -1 {
+		t.Errorf("右连接 EstimatedRows = %d, want -1", right.EstimatedRows)
+	}
+	if got := left.Children[1].EstimatedRows; got != 4 {
+		t.Errorf("Inexact(4) 解析为 %d", got)
+	}
+	if got := right.Children[0].Type; got != "CsvExec" {
+		t.Errorf("右连接的第一个子节点 = %s, want CsvExec", got)
+	}
+	if !strings.Contains(right.Children[0].Details, "projection=[c, d]") {
+		t.Errorf("Details = %q", right.Children[0].Details)
+	}
+
+	if got := plan.String(); got != nestedJoinPlan {
+		t.Errorf("String() 与原计划不一致:\n%s", got)
+	}
+}
+
+func TestPlanFromResponse(t *testing.T) {
+	resp := &QueryResponse{
+		Columns: []Column{{Name: "plan_type"}, {Name: "plan"}},
+		Rows: []Row{
+			{"logical_plan", "Projection: a"},
+			{"physical_plan", "ProjectionExec: expr=[a]\n  MemoryExec: partitions=1"},
+		},
+	}
+	plan, err := planFromResponse(resp)
+	if err != nil {
+		t.Fatalf("planFromResponse: %v", err)
+	}
+	if plan.Root.Type != "ProjectionExec" || len(plan.Root.Children) != 1 {
+		t.Errorf("计划 = %s", plan)
+	}
+}
+
+func TestPlanFromResponseTextOnly(t *testing.T) {
+	plan, err := planFromResponse(&QueryResponse{Result: "  some plan \n"})
+	if err != nil {
+		t.Fatalf("planFromResponse: %v", err)
+	}
+	if plan.Root.Details != "some plan" || plan.Root.Type != "" {
+		t.Errorf("Root = %+v", plan.Root)
+	}
+}
+
+func TestPlanFromResponseShortRow(t *testing.T) {
+	resp := &QueryResponse{
+		Columns: []Column{{Name: "plan_type"}, {Name: "plan"}},
+		Rows:    []Row{{"physical_plan"}},
+	}
+	if _, err := planFromResponse(resp); err == nil {
+		t.Fatal("列数不足的行应返回错误")
+	}
+}
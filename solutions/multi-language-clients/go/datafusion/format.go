@@ -0,0 +1,226 @@
+package datafusion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ResultFormat 是结果的输出格式。
+type ResultFormat int
+
+const (
+	// FormatTable 按列对齐输出，NULL 显示为 NULL
+	FormatTable ResultFormat = iota
+	// FormatJSON 输出行对象数组，NULL 输出为 null
+	FormatJSON
+	// FormatCSV 输出带表头的 RFC 4180 CSV，NULL 输出为空字段
+	FormatCSV
+	// FormatNDJSON 每行输出一个 JSON 对象，以换行分隔
+	FormatNDJSON
+)
+
+func (f ResultFormat) String() string {
+	switch f {
+	case FormatTable:
+		return "table"
+	case FormatJSON:
+		return "json"
+	case FormatCSV:
+		return "csv"
+	case FormatNDJSON:
+		return "ndjson"
+	default:
+		return fmt.Sprintf("ResultFormat(%d)", int(f))
+	}
+}
+
+// ParseResultFormat 解析格式名 table、json、ndjson 或 csv。
+func ParseResultFormat(name string) (ResultFormat, error) {
+	switch strings.ToLower(name) {
+	case "table":
+		return FormatTable, nil
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson":
+		return FormatNDJSON, nil
+	default:
+		return 0, fmt.Errorf("未知的输出格式: %q", name)
+	}
+}
+
+// FormatResult 将查询结果按 format 写入 w。
+func FormatResult(w io.Writer, resp *QueryResponse, format ResultFormat) error {
+	switch format {
+	case FormatTable:
+		return formatTable(w, resp)
+	case FormatJSON:
+		return formatJSON(w, resp)
+	case FormatCSV:
+		return formatCSV(w, resp)
+	case FormatNDJSON:
+		return formatNDJSON(w, resp)
+	default:
+		return fmt.Errorf("未知的输出格式: %s", format)
+	}
+}
+
+func formatTable(w io.Writer, resp *QueryResponse) error {
+	// 没有结构化结果时输出文本结果
+	if len(resp.Columns) == 0 {
+		if resp.Result == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, resp.Result)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	names := make([]string, len(resp.Columns))
+	rules := make([]string, len(resp.Columns))
+	for i, col := range resp.Columns {
+		names[i] = col.Name
+		rules[i] = strings.Repeat("-", len(col.Name))
+	}
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+	fmt.Fprintln(tw, strings.Join(rules, "\t"))
+
+	cells := make([]string, len(resp.Columns))
+	for _, row := range resp.Rows {
+		for i := range cells {
+			cells[i] = "NULL"
+			if i < len(row) && row[i] != nil {
+				cells[i] = formatValue(row[i])
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatJSON(w io.Writer, resp *QueryResponse) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for r, row := range resp.Rows {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		obj, err := rowJSON(resp.Columns, row)
+		if err != nil {
+			return err
+		}
+		b.WriteString("\n  ")
+		b.Write(obj)
+	}
+	if len(resp.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatNDJSON(w io.Writer, resp *QueryResponse) error {
+	b, err := ndjsonLines(resp.Columns, resp.Rows)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ndjsonLines 将 rows 编码为以换行结尾的 JSON 对象序列
+func ndjsonLines(cols []Column, rows []Row) ([]byte, error) {
+	var b bytes.Buffer
+	for _, row := range rows {
+		obj, err := rowJSON(cols, row)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(obj)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// rowJSON 按列顺序将一行编码为 JSON 对象
+func rowJSON(cols []Column, row Row) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, col := range cols {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if i < len(row) {
+			v = row[i]
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("列 %s: %w", col.Name, err)
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(val)
+	}
+	b.WriteString("}")
+	return []byte(b.String()), nil
+}
+
+func formatCSV(w io.Writer, resp *QueryResponse) error {
+	cw := csv.NewWriter(w)
+	names := make([]string, len(resp.Columns))
+	for i, col := range resp.Columns {
+		names[i] = col.Name
+	}
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+
+	record := make([]string, len(resp.Columns))
+	for _, row := range resp.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) && row[i] != nil {
+				record[i] = formatValue(row[i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatValue 将非 NULL 值格式化为文本
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return fmt.Sprintf("\\x%x", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,78 @@
+package datafusion
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 下的 golden 文件")
+
+// goldenResult 是格式化测试使用的固定结果集，覆盖 NULL、转义与各种值类型
+func goldenResult() *QueryResponse {
+	return &QueryResponse{
+		Columns: []Column{
+			{Name: "id", DataType: "Int64"},
+			{Name: "name", DataType: "Utf8", Nullable: true},
+			{Name: "score", DataType: "Float64"},
+			{Name: "active", DataType: "Boolean"},
+			{Name: "created", DataType: "Timestamp(Microsecond, None)"},
+		},
+		Rows: []Row{
+			{int64(1), "alice", 9.5, true, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			{int64(2), nil, 7.25, false, time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)},
+			{int64(3), "o'brien, \"bob\"", 0.0, true, time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)},
+		},
+	}
+}
+
+func TestFormatResultGolden(t *testing.T) {
+	formats := []ResultFormat{FormatTable, FormatJSON, FormatCSV, FormatNDJSON}
+	for _, f := range formats {
+		t.Run(f.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := FormatResult(&buf, goldenResult(), f); err != nil {
+				t.Fatalf("FormatResult: %v", err)
+			}
+
+			path := filepath.Join("testdata", "format."+f.String()+".golden")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("读取 golden 文件失败: %v", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("输出与 %s 不一致:\n got:\n%s\nwant:\n%s", path, got, want)
+			}
+		})
+	}
+}
+
+func TestParseResultFormat(t *testing.T) {
+	for _, f := range []ResultFormat{FormatTable, FormatJSON, FormatCSV, FormatNDJSON} {
+		got, err := ParseResultFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseResultFormat(%q) = %v, %v", f.String(), got, err)
+		}
+	}
+	if _, err := ParseResultFormat("xml"); err == nil {
+		t.Error("ParseResultFormat(xml) 应返回错误")
+	}
+}
+
+func TestFormatTableTextResult(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatResult(&buf, &QueryResponse{Result: "OK"}, FormatTable); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "OK\n" {
+		t.Errorf("输出 = %q, want %q", buf.String(), "OK\n")
+	}
+}
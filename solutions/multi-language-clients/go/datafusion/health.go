@@ -0,0 +1,130 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// 健康检查流断开后重新订阅的间隔
+const healthRewatchInterval = time.Second
+
+// HealthStatus 是 grpc.health.v1 报告的服务状态。
+type HealthStatus int32
+
+const (
+	// HealthUnknown 表示状态未知，例如服务端未注册该服务或尚未收到状态
+	HealthUnknown HealthStatus = iota
+	HealthServing
+	HealthNotServing
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthServing:
+		return "serving"
+	case HealthNotServing:
+		return "not-serving"
+	default:
+		return "unknown"
+	}
+}
+
+func healthStatusFromPB(s healthpb.HealthCheckResponse_ServingStatus) HealthStatus {
+	switch s {
+	case healthpb.HealthCheckResponse_SERVING:
+		return HealthServing
+	case healthpb.HealthCheckResponse_NOT_SERVING:
+		return HealthNotServing
+	default:
+		return HealthUnknown
+	}
+}
+
+// WithHealthCheck 让 Pool 订阅每个端点的 grpc.health.v1 Watch，
+// 报告 NOT_SERVING 的端点不参与选择，恢复 SERVING 后重新加入。
+// service 为空表示服务端整体状态。服务端未实现健康检查时不做限制。
+func WithHealthCheck(service string) Option {
+	return func(o *options) {
+		o.healthCheck = true
+		o.healthService = service
+	}
+}
+
+// HealthCheck 调用标准的 grpc.health.v1 Health/Check 查询 service 的状态。
+// service 为空表示服务端整体状态。
+func (c *DataFusionClient) HealthCheck(ctx context.Context, service string) (HealthStatus, error) {
+	resp, err := healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return HealthUnknown, fmt.Errorf("健康检查失败: %w", err)
+	}
+	return healthStatusFromPB(resp.GetStatus()), nil
+}
+
+// WatchHealth 通过 Health/Watch 订阅 service 的状态变化。
+// 返回的通道先收到当前状态，之后每次变化收到一次；
+// ctx 结束或流断开时通道关闭，流异常断开前会先发送 HealthUnknown。
+func (c *DataFusionClient) WatchHealth(ctx context.Context, service string) (<-chan HealthStatus, error) {
+	stream, err := healthpb.NewHealthClient(c.conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return nil, fmt.Errorf("订阅健康状态失败: %w", err)
+	}
+	// 服务端会立即发送当前状态，首条消息的错误 (如 Unimplemented) 直接返回给调用方
+	first, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("订阅健康状态失败: %w", err)
+	}
+
+	ch := make(chan HealthStatus, 1)
+	ch <- healthStatusFromPB(first.GetStatus())
+	go func() {
+		defer close(ch)
+		for {
+			resp, err := stream.Recv()
+			s := HealthUnknown
+			if err == nil {
+				s = healthStatusFromPB(resp.GetStatus())
+			} else if ctx.Err() != nil {
+				return
+			}
+			select {
+			case ch <- s:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// watchHealth 持续记录端点的健康状态，直到 ctx 结束或连接关闭
+func (ep *endpoint) watchHealth(ctx context.Context, service string) {
+	for ctx.Err() == nil && ep.client.conn.GetState() != connectivity.Shutdown {
+		ch, err := ep.client.WatchHealth(ctx, service)
+		if status.Code(err) == codes.Unimplemented {
+			// 服务端不支持健康检查，不做限制
+			ep.health.Store(int32(HealthUnknown))
+			return
+		}
+		if err == nil {
+			for s := range ch {
+				ep.health.Store(int32(s))
+			}
+		} else {
+			ep.health.Store(int32(HealthUnknown))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(healthRewatchInterval):
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package datafusion
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"datafusion-client/pb"
+)
+
+// serveWithHealth 启动同时注册了 srv 和标准健康检查服务的服务端
+func serveWithHealth(t *testing.T, srv pb.DataFusionServer) (string, *health.Server) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	pb.RegisterDataFusionServer(s, srv)
+	healthpb.RegisterHealthServer(s, hs)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), hs
+}
+
+// waitHealth 等待连接池的第 i 个端点报告 want
+func waitHealth(t *testing.T, p *Pool, i int, want HealthStatus) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if p.Stats()[i].Health == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("端点 %d 的健康状态 = %v, want %v", i, p.Stats()[i].Health, want)
+}
+
+func TestPoolSkipsNotServingEndpoint(t *testing.T) {
+	addrA, _ := serveWithHealth(t, (&namedServer{name: "a"}).fake())
+	addrB, healthB := serveWithHealth(t, (&namedServer{name: "b"}).fake())
+	p, err := NewPool([]string{addrA, addrB}, WithInsecure(), WithHealthCheck(""))
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer p.Close()
+	waitHealth(t, p, 1, HealthServing)
+
+	healthB.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	waitHealth(t, p, 1, HealthNotServing)
+	for _, name := range poolAnswers(t, p, 4) {
+		if name != "a" {
+			t.Fatalf("NOT_SERVING 的端点 b 仍被选中")
+		}
+	}
+	if p.Stats()[1].Healthy {
+		t.Error("NOT_SERVING 的端点报告为健康")
+	}
+
+	healthB.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	waitHealth(t, p, 1, HealthServing)
+	counts := map[string]int{}
+	for _, name := range poolAnswers(t, p, 4) {
+		counts[name]++
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("恢复后的应答分布 = %v, want a 和 b 各 2 次", counts)
+	}
+}
+
+func TestPoolHealthWithoutHealthService(t *testing.T) {
+	// 服务端未实现健康检查时不做限制
+	p, err := NewPool([]string{startServer(t, (&namedServer{name: "a"}).fake())}, WithInsecure(), WithHealthCheck(""))
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer p.Close()
+	if got := poolAnswers(t, p, 2); got[0] != "a" || got[1] != "a" {
+		t.Errorf("应答 = %v", got)
+	}
+	if h := p.Stats()[0].Health; h != HealthUnknown {
+		t.Errorf("Health = %v, want HealthUnknown", h)
+	}
+}
+
+func TestPoolCloseStopsHealthWatchers(t *testing.T) {
+	addr, _ := serveWithHealth(t, (&namedServer{name: "a"}).fake())
+	p, err := NewPool([]string{addr}, WithInsecure(), WithHealthCheck(""))
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	waitHealth(t, p, 0, HealthServing)
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close 未等到健康检查协程退出")
+	}
+	if err := p.watchCtx.Err(); err == nil {
+		t.Error("Close 后健康检查的上下文未结束")
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	addr, hs := serveWithHealth(t, (&namedServer{name: "a"}).fake())
+	hs.SetServingStatus("datafusion", healthpb.HealthCheckResponse_NOT_SERVING)
+	c := dialTestClient(t, addr)
+
+	ctx := context.Background()
+	if got, err := c.HealthCheck(ctx, ""); err != nil || got != HealthServing {
+		t.Errorf("HealthCheck(\"\") = %v, %v, want SERVING", got, err)
+	}
+	if got, err := c.HealthCheck(ctx, "datafusion"); err != nil || got != HealthNotServing {
+		t.Errorf("HealthCheck(datafusion) = %v, %v, want NOT_SERVING", got, err)
+	}
+}
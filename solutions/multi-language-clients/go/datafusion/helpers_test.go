@@ -0,0 +1,290 @@
+package datafusion
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"datafusion-client/pb"
+)
+
+// startServer 在随机端口上启动 srv，测试结束时停止
+func startServer(t *testing.T, srv pb.DataFusionServer) string {
+	t.Helper()
+	addr, _ := serve(t, "127.0.0.1:0", srv)
+	return addr
+}
+
+// serve 在 addr 上启动 srv，返回实际监听的地址和服务器，测试结束时停止。
+// 用于模拟服务端重启：Stop 之后在同一地址再次调用 serve
+func serve(t *testing.T, addr string, srv pb.DataFusionServer) (string, *grpc.Server) {
+	t.Helper()
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterDataFusionServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), s
+}
+
+// newTestClient 启动 srv 并返回连接到它的明文客户端
+func newTestClient(t *testing.T, srv pb.DataFusionServer, opts ...Option) *DataFusionClient {
+	t.Helper()
+	return dialTestClient(t, startServer(t, srv), opts...)
+}
+
+// dialTestClient 返回连接到 addr 的明文客户端，测试结束时关闭
+func dialTestClient(t *testing.T, addr string, opts ...Option) *DataFusionClient {
+	t.Helper()
+	c, err := NewClient(context.Background(), addr, append([]Option{WithInsecure()}, opts...)...)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// unusedAddr 返回当前没有服务监听的本地地址
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+// waitDisconnected 等待客户端发现连接已断开
+func waitDisconnected(t *testing.T, c *DataFusionClient) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for state := c.State(); state == connectivity.Ready; state = c.State() {
+		if !c.conn.WaitForStateChange(ctx, state) {
+			t.Fatal("等待连接断开超时")
+		}
+	}
+}
+
+// mustPBValue 将 Go 值转换为 protobuf 值，nil 表示 NULL
+func mustPBValue(v any) *pb.Value {
+	pv, err := toPBValue(v)
+	if err != nil {
+		panic(err)
+	}
+	return pv
+}
+
+// fakeServer 是按 RPC 设置处理函数的测试服务端，未设置的方法返回 Unimplemented。
+// 测试不各自实现 pb.DataFusionServer：需要记录状态的测试服务端
+// 提供 fake 方法，把自己的处理函数接到 fakeServer 上
+type fakeServer struct {
+	pb.UnimplementedDataFusionServer
+	executeQuery        func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error)
+	executeMulti        func(*pb.QueryRequest, pb.DataFusion_ExecuteMultiServer) error
+	queryStream         func(*pb.QueryRequest, pb.DataFusion_QueryStreamServer) error
+	prepare             func(context.Context, *pb.PrepareRequest) (*pb.PrepareResponse, error)
+	execPrepared        func(context.Context, *pb.ExecPreparedRequest) (*pb.QueryResponse, error)
+	executeBatch        func(context.Context, *pb.BatchRequest) (*pb.BatchResponse, error)
+	listTables          func(context.Context, *pb.ListTablesRequest) (*pb.ListTablesResponse, error)
+	describeTable       func(context.Context, *pb.DescribeTableRequest) (*pb.DescribeTableResponse, error)
+	listMembers         func(context.Context, *pb.ListMembersRequest) (*pb.ListMembersResponse, error)
+	cancelQuery         func(context.Context, *pb.CancelQueryRequest) (*pb.CancelQueryResponse, error)
+	beginTransaction    func(context.Context, *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error)
+	commitTransaction   func(context.Context, *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error)
+	rollbackTransaction func(context.Context, *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error)
+	openCursor          func(context.Context, *pb.OpenCursorRequest) (*pb.Page, error)
+	fetchPage           func(context.Context, *pb.FetchPageRequest) (*pb.Page, error)
+	closeCursor         func(context.Context, *pb.CloseCursorRequest) (*pb.CloseCursorResponse, error)
+	submitQuery         func(context.Context, *pb.SubmitQueryRequest) (*pb.SubmitQueryResponse, error)
+	getQueryStatus      func(context.Context, *pb.QueryStatusRequest) (*pb.QueryStatusResponse, error)
+	fetchResult         func(context.Context, *pb.FetchResultRequest) (*pb.QueryResponse, error)
+	estimateCost        func(context.Context, *pb.EstimateCostRequest) (*pb.CostEstimate, error)
+}
+
+func (s *fakeServer) ExecuteQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if s.executeQuery == nil {
+		return s.UnimplementedDataFusionServer.ExecuteQuery(ctx, req)
+	}
+	return s.executeQuery(ctx, req)
+}
+
+func (s *fakeServer) ExecuteMulti(req *pb.QueryRequest, stream pb.DataFusion_ExecuteMultiServer) error {
+	if s.executeMulti == nil {
+		return s.UnimplementedDataFusionServer.ExecuteMulti(req, stream)
+	}
+	return s.executeMulti(req, stream)
+}
+
+func (s *fakeServer) QueryStream(req *pb.QueryRequest, stream pb.DataFusion_QueryStreamServer) error {
+	if s.queryStream == nil {
+		return s.UnimplementedDataFusionServer.QueryStream(req, stream)
+	}
+	return s.queryStream(req, stream)
+}
+
+func (s *fakeServer) Prepare(ctx context.Context, req *pb.PrepareRequest) (*pb.PrepareResponse, error) {
+	if s.prepare == nil {
+		return s.UnimplementedDataFusionServer.Prepare(ctx, req)
+	}
+	return s.prepare(ctx, req)
+}
+
+func (s *fakeServer) ExecPrepared(ctx context.Context, req *pb.ExecPreparedRequest) (*pb.QueryResponse, error) {
+	if s.execPrepared == nil {
+		return s.UnimplementedDataFusionServer.ExecPrepared(ctx, req)
+	}
+	return s.execPrepared(ctx, req)
+}
+
+func (s *fakeServer) ExecuteBatch(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	if s.executeBatch == nil {
+		return s.UnimplementedDataFusionServer.ExecuteBatch(ctx, req)
+	}
+	return s.executeBatch(ctx, req)
+}
+
+func (s *fakeServer) ListTables(ctx context.Context, req *pb.ListTablesRequest) (*pb.ListTablesResponse, error) {
+	if s.listTables == nil {
+		return s.UnimplementedDataFusionServer.ListTables(ctx, req)
+	}
+	return s.listTables(ctx, req)
+}
+
+func (s *fakeServer) DescribeTable(ctx context.Context, req *pb.DescribeTableRequest) (*pb.DescribeTableResponse, error) {
+	if s.describeTable == nil {
+		return s.UnimplementedDataFusionServer.DescribeTable(ctx, req)
+	}
+	return s.describeTable(ctx, req)
+}
+
+func (s *fakeServer) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	if s.listMembers == nil {
+		return s.UnimplementedDataFusionServer.ListMembers(ctx, req)
+	}
+	return s.listMembers(ctx, req)
+}
+
+func (s *fakeServer) CancelQuery(ctx context.Context, req *pb.CancelQueryRequest) (*pb.CancelQueryResponse, error) {
+	if s.cancelQuery == nil {
+		return s.UnimplementedDataFusionServer.CancelQuery(ctx, req)
+	}
+	return s.cancelQuery(ctx, req)
+}
+
+func (s *fakeServer) BeginTransaction(ctx context.Context, req *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
+	if s.beginTransaction == nil {
+		return s.UnimplementedDataFusionServer.BeginTransaction(ctx, req)
+	}
+	return s.beginTransaction(ctx, req)
+}
+
+func (s *fakeServer) CommitTransaction(ctx context.Context, req *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error) {
+	if s.commitTransaction == nil {
+		return s.UnimplementedDataFusionServer.CommitTransaction(ctx, req)
+	}
+	return s.commitTransaction(ctx, req)
+}
+
+func (s *fakeServer) RollbackTransaction(ctx context.Context, req *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error) {
+	if s.rollbackTransaction == nil {
+		return s.UnimplementedDataFusionServer.RollbackTransaction(ctx, req)
+	}
+	return s.rollbackTransaction(ctx, req)
+}
+
+func (s *fakeServer) OpenCursor(ctx context.Context, req *pb.OpenCursorRequest) (*pb.Page, error) {
+	if s.openCursor == nil {
+		return s.UnimplementedDataFusionServer.OpenCursor(ctx, req)
+	}
+	return s.openCursor(ctx, req)
+}
+
+func (s *fakeServer) FetchPage(ctx context.Context, req *pb.FetchPageRequest) (*pb.Page, error) {
+	if s.fetchPage == nil {
+		return s.UnimplementedDataFusionServer.FetchPage(ctx, req)
+	}
+	return s.fetchPage(ctx, req)
+}
+
+func (s *fakeServer) CloseCursor(ctx context.Context, req *pb.CloseCursorRequest) (*pb.CloseCursorResponse, error) {
+	if s.closeCursor == nil {
+		return s.UnimplementedDataFusionServer.CloseCursor(ctx, req)
+	}
+	return s.closeCursor(ctx, req)
+}
+
+func (s *fakeServer) SubmitQuery(ctx context.Context, req *pb.SubmitQueryRequest) (*pb.SubmitQueryResponse, error) {
+	if s.submitQuery == nil {
+		return s.UnimplementedDataFusionServer.SubmitQuery(ctx, req)
+	}
+	return s.submitQuery(ctx, req)
+}
+
+func (s *fakeServer) GetQueryStatus(ctx context.Context, req *pb.QueryStatusRequest) (*pb.QueryStatusResponse, error) {
+	if s.getQueryStatus == nil {
+		return s.UnimplementedDataFusionServer.GetQueryStatus(ctx, req)
+	}
+	return s.getQueryStatus(ctx, req)
+}
+
+func (s *fakeServer) FetchResult(ctx context.Context, req *pb.FetchResultRequest) (*pb.QueryResponse, error) {
+	if s.fetchResult == nil {
+		return s.UnimplementedDataFusionServer.FetchResult(ctx, req)
+	}
+	return s.fetchResult(ctx, req)
+}
+
+func (s *fakeServer) EstimateCost(ctx context.Context, req *pb.EstimateCostRequest) (*pb.CostEstimate, error) {
+	if s.estimateCost == nil {
+		return s.UnimplementedDataFusionServer.EstimateCost(ctx, req)
+	}
+	return s.estimateCost(ctx, req)
+}
+
+// arrowIPC 将一个 id (Int64) / name (Utf8，可为空) 的记录批编码为 Arrow IPC 流，
+// names 中的 nil 表示 NULL
+func arrowIPC(t *testing.T, ids []int64, names []*string) []byte {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	nb := b.Field(1).(*array.StringBuilder)
+	for _, n := range names {
+		if n == nil {
+			nb.AppendNull()
+		} else {
+			nb.Append(*n)
+		}
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	if err := w.Write(rec); err != nil {
+		t.Fatalf("写入 Arrow 记录失败: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("关闭 Arrow 写入器失败: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,47 @@
+package datafusion
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks 是查询生命周期的回调，未设置的回调被跳过。
+// 回调在发起查询的协程中同步执行，不应阻塞。
+type Hooks struct {
+	// OnQueryStart 在查询发送前调用
+	OnQueryStart func(ctx context.Context, sql string)
+	// OnQueryEnd 在查询结束时调用，流式查询在结果流结束或关闭时调用，
+	// 多语句查询在所有结果读完、某条语句失败或关闭时调用一次；
+	// rows 是收到的行数；结果流未读完就被关闭时 err 为 ErrStreamClosed
+	OnQueryEnd func(ctx context.Context, sql string, rows int, err error, dur time.Duration)
+	// OnRetry 在失败的请求即将重试前调用，attempt 是刚失败的第几次尝试 (从 1 开始)
+	OnRetry func(attempt int, err error)
+}
+
+// WithHooks 设置查询生命周期的回调，适合不使用 OpenTelemetry 或 Prometheus 时接入自定义逻辑。
+// 回调覆盖所有执行查询的调用：ExecuteQuery、ExecuteQueryStream、ExecuteMulti、
+// ExecuteQueryArrow、ExecuteBatch、预编译语句、事务内的查询、游标的每一页以及 FetchResult。
+// 批量执行按一次查询回调，sql 为以 "; " 连接的各条语句；FetchResult 的 sql 为空。
+func WithHooks(h Hooks) Option {
+	return func(o *options) {
+		o.hooks = h
+	}
+}
+
+func (h *Hooks) queryStart(ctx context.Context, sql string) {
+	if h.OnQueryStart != nil {
+		h.OnQueryStart(ctx, sql)
+	}
+}
+
+func (h *Hooks) queryEnd(ctx context.Context, sql string, rows int, err error, dur time.Duration) {
+	if h.OnQueryEnd != nil {
+		h.OnQueryEnd(ctx, sql, rows, err, dur)
+	}
+}
+
+func (h *Hooks) retry(attempt int, err error) {
+	if h.OnRetry != nil {
+		h.OnRetry(attempt, err)
+	}
+}
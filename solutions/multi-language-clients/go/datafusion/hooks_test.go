@@ -0,0 +1,319 @@
+package datafusion
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// queryEndRecord 是一次 OnQueryEnd 回调的参数
+type queryEndRecord struct {
+	sql  string
+	rows int
+	err  error
+}
+
+// retryRecord 是一次 OnRetry 回调的参数
+type retryRecord struct {
+	attempt int
+	code    codes.Code
+}
+
+// lifecycleRecorder 按调用顺序记录 Hooks 的各个回调
+type lifecycleRecorder struct {
+	mu      sync.Mutex
+	events  []string
+	starts  []string
+	ends    []queryEndRecord
+	retries []retryRecord
+	// requestIDs 是各回调上下文中的请求 ID，OnRetry 没有上下文
+	requestIDs []string
+	durations  []time.Duration
+}
+
+func (r *lifecycleRecorder) hooks() Hooks {
+	return Hooks{
+		OnQueryStart: func(ctx context.Context, sql string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, "start")
+			r.starts = append(r.starts, sql)
+			r.requestIDs = append(r.requestIDs, requestID(ctx))
+		},
+		OnQueryEnd: func(ctx context.Context, sql string, rows int, err error, dur time.Duration) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, "end")
+			r.ends = append(r.ends, queryEndRecord{sql: sql, rows: rows, err: err})
+			r.requestIDs = append(r.requestIDs, requestID(ctx))
+			r.durations = append(r.durations, dur)
+		},
+		OnRetry: func(attempt int, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, "retry")
+			r.retries = append(r.retries, retryRecord{attempt: attempt, code: status.Code(err)})
+		},
+	}
+}
+
+func (r *lifecycleRecorder) snapshot() ([]string, []queryEndRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.starts...), append([]queryEndRecord(nil), r.ends...)
+}
+
+// order 返回回调的调用顺序，如 "start retry end"
+func (r *lifecycleRecorder) order() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, " ")
+}
+
+func TestHooksSuccessfulQuery(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	c := newTestClient(t, scanServer(), WithHooks(rec.hooks()))
+
+	const sql = "SELECT name, age, city FROM users"
+	resp, err := c.ExecuteQuery(context.Background(), sql)
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	if got := rec.order(); got != "start end" {
+		t.Fatalf("回调顺序 = %q, want \"start end\"", got)
+	}
+	starts, ends := rec.snapshot()
+	if starts[0] != sql {
+		t.Errorf("OnQueryStart sql = %q", starts[0])
+	}
+	if ends[0] != (queryEndRecord{sql: sql, rows: 2}) {
+		t.Errorf("OnQueryEnd = %+v, want rows=2 且 err=nil", ends[0])
+	}
+	if rec.durations[0] <= 0 {
+		t.Errorf("OnQueryEnd dur = %v, want > 0", rec.durations[0])
+	}
+	for i, id := range rec.requestIDs {
+		if id != resp.RequestID {
+			t.Errorf("回调 %d 的请求 ID = %q, want %q", i, id, resp.RequestID)
+		}
+	}
+	if len(rec.retries) != 0 {
+		t.Errorf("成功的查询触发了 OnRetry: %+v", rec.retries)
+	}
+}
+
+func TestHooksRetriedFailure(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	srv := &flakyServer{failures: 5, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond), WithHooks(rec.hooks()))
+
+	_, err := c.ExecuteQuery(context.Background(), "SELECT 1")
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+
+	// 重试发生在同一次查询内部，开始和结束各只调用一次
+	if got := rec.order(); got != "start retry retry end" {
+		t.Fatalf("回调顺序 = %q, want \"start retry retry end\"", got)
+	}
+	want := []retryRecord{{1, codes.Unavailable}, {2, codes.Unavailable}}
+	for i, r := range rec.retries {
+		if r != want[i] {
+			t.Errorf("OnRetry #%d = %+v, want %+v", i+1, r, want[i])
+		}
+	}
+	_, ends := rec.snapshot()
+	if ends[0].sql != "SELECT 1" || ends[0].rows != 0 || status.Code(ends[0].err) != codes.Unavailable {
+		t.Errorf("OnQueryEnd = %+v, want Unavailable", ends[0])
+	}
+	if ends[0].err != err {
+		t.Errorf("OnQueryEnd err = %v, want 与返回值相同", ends[0].err)
+	}
+}
+
+func TestHooksRetryThenSucceed(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	srv := &flakyServer{failures: 1, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond), WithHooks(rec.hooks()))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got := rec.order(); got != "start retry end" {
+		t.Fatalf("回调顺序 = %q, want \"start retry end\"", got)
+	}
+	if _, ends := rec.snapshot(); ends[0].err != nil {
+		t.Errorf("OnQueryEnd err = %v, want nil", ends[0].err)
+	}
+}
+
+func TestHooksStreamEndsAtCompletion(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	c := newTestClient(t, (&streamServer{rows: 5, batchSize: 2}).fake(), WithHooks(rec.hooks()))
+
+	const sql = "SELECT id, name FROM t"
+	s, err := c.ExecuteQueryStream(context.Background(), sql)
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if got := rec.order(); got != "start" {
+		t.Fatalf("读完前的回调 = %q, want 只有 start", got)
+	}
+	for {
+		if _, err := s.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+	s.Close()
+
+	if got := rec.order(); got != "start end" {
+		t.Fatalf("回调顺序 = %q, want \"start end\"", got)
+	}
+	if _, ends := rec.snapshot(); ends[0] != (queryEndRecord{sql: sql, rows: 5}) {
+		t.Errorf("OnQueryEnd = %+v, want rows=5 且 err=nil", ends[0])
+	}
+	if rec.requestIDs[0] == "" || rec.requestIDs[1] != s.RequestID() {
+		t.Errorf("请求 ID = %q, want %q", rec.requestIDs, s.RequestID())
+	}
+}
+
+func TestHooksNilCallbacksSkipped(t *testing.T) {
+	var retries int
+	srv := &flakyServer{failures: 1, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(2, time.Millisecond), WithHooks(Hooks{
+		OnRetry: func(int, error) { retries++ },
+	}))
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if retries != 1 {
+		t.Errorf("OnRetry 调用了 %d 次, want 1", retries)
+	}
+}
+
+// entryPointServer 对各类查询 RPC 返回两行结果
+func entryPointServer(t *testing.T) *fakeServer {
+	rows := []*pb.Row{
+		{Values: []*pb.Value{mustPBValue(int64(1))}},
+		{Values: []*pb.Value{mustPBValue(int64(2))}},
+	}
+	alice, bob := "alice", "bob"
+	return &fakeServer{
+		executeQuery: func(_ context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+			if req.GetEncoding() == pb.ResultEncoding_RESULT_ENCODING_ARROW_IPC {
+				return &pb.QueryResponse{ArrowIpc: arrowIPC(t, []int64{1, 2}, []*string{&alice, &bob})}, nil
+			}
+			return &pb.QueryResponse{Rows: rows}, nil
+		},
+		prepare: func(context.Context, *pb.PrepareRequest) (*pb.PrepareResponse, error) {
+			return &pb.PrepareResponse{Handle: "h-1"}, nil
+		},
+		execPrepared: func(context.Context, *pb.ExecPreparedRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{Rows: rows}, nil
+		},
+		executeBatch: func(_ context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+			resp := &pb.BatchResponse{}
+			for range req.GetSqls() {
+				resp.Results = append(resp.Results, &pb.BatchItem{Outcome: &pb.BatchItem_Response{Response: &pb.QueryResponse{Rows: rows[:1]}}})
+			}
+			return resp, nil
+		},
+		openCursor: func(context.Context, *pb.OpenCursorRequest) (*pb.Page, error) {
+			return &pb.Page{Rows: rows, NextToken: "t-1"}, nil
+		},
+		fetchPage: func(context.Context, *pb.FetchPageRequest) (*pb.Page, error) {
+			return &pb.Page{Rows: rows}, nil
+		},
+		fetchResult: func(context.Context, *pb.FetchResultRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{Rows: rows}, nil
+		},
+	}
+}
+
+func TestHooksCoverAllEntryPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		call func(ctx context.Context, c *DataFusionClient) error
+	}{
+		{"Arrow", "SELECT id, name FROM t", func(ctx context.Context, c *DataFusionClient) error {
+			rec, err := c.ExecuteQueryArrow(ctx, "SELECT id, name FROM t")
+			if err == nil {
+				rec.Release()
+			}
+			return err
+		}},
+		{"Prepared", "SELECT id FROM t WHERE id > $1", func(ctx context.Context, c *DataFusionClient) error {
+			stmt, err := c.Prepare(ctx, "SELECT id FROM t WHERE id > $1")
+			if err != nil {
+				return err
+			}
+			_, err = stmt.Query(ctx, 0)
+			return err
+		}},
+		{"Batch", "SELECT 1; SELECT 2", func(ctx context.Context, c *DataFusionClient) error {
+			_, err := c.ExecuteBatch(ctx, []string{"SELECT 1", "SELECT 2"})
+			return err
+		}},
+		{"CursorPage", "SELECT id FROM t", func(ctx context.Context, c *DataFusionClient) error {
+			cur, err := c.QueryPaged(ctx, "SELECT id FROM t", 2)
+			if err != nil {
+				return err
+			}
+			// 首页随 OpenCursor 返回，第二页才会再次请求
+			if _, err := cur.NextPage(ctx); err != nil {
+				return err
+			}
+			_, err = cur.NextPage(ctx)
+			return err
+		}},
+		{"FetchResult", "", func(ctx context.Context, c *DataFusionClient) error {
+			_, err := c.FetchResult(ctx, "bg-1")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &lifecycleRecorder{}
+			reg := prometheus.NewRegistry()
+			spans := &spanRecorder{}
+			c := newTestClient(t, entryPointServer(t), WithHooks(rec.hooks()), WithMetrics(reg), WithTracing(spans))
+
+			if err := tt.call(context.Background(), c); err != nil {
+				t.Fatalf("调用失败: %v", err)
+			}
+			starts, ends := rec.snapshot()
+			if len(starts) == 0 || len(starts) != len(ends) {
+				t.Fatalf("OnQueryStart %d 次、OnQueryEnd %d 次, want 成对调用", len(starts), len(ends))
+			}
+			for i, end := range ends {
+				if starts[i] != tt.sql || end != (queryEndRecord{sql: tt.sql, rows: 2}) {
+					t.Errorf("第 %d 次回调: start %q, end %+v, want sql=%q rows=2", i+1, starts[i], end, tt.sql)
+				}
+			}
+			if got := testutil.ToFloat64(c.metrics.queries.WithLabelValues(methodUnary)); int(got) != len(ends) {
+				t.Errorf("queries{method=unary} = %v, want %d", got, len(ends))
+			}
+			if got := len(spans.named(spanName)); got != len(ends) {
+				t.Errorf("%s span 数量 = %d, want %d", spanName, got, len(ends))
+			}
+		})
+	}
+}
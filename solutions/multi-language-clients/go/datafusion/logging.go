@@ -0,0 +1,69 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// Logger 是客户端使用的结构化日志接口，kv 为交替出现的键和值。
+// *slog.Logger 直接满足该接口。
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// WithLogger 设置客户端日志，默认不输出任何日志。
+// 完整的 SQL 只在 Debug 级别输出，其余级别只记录 SQL 的哈希。
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		o.logger = l
+	}
+}
+
+// NewSlogLogger 返回输出到 l 的 Logger，l 为 nil 时使用 slog.Default()。
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// logQuery 记录一次结束的查询：成功只输出 Debug，失败按原因输出 Warn 或 Error
+func (c *DataFusionClient) logQuery(ctx context.Context, method, sql string, err error, dur time.Duration) {
+	log := c.opts.logger
+	log.Debug("查询结束", "method", method, "sql", sql, "request_id", requestID(ctx), "duration", dur, "error", err)
+	// 提前关闭结果流是正常用法，不作为失败记录
+	if err == nil || errors.Is(err, ErrStreamClosed) {
+		return
+	}
+
+	kv := []any{
+		"method", method,
+		"sql_hash", sqlHash(sql),
+		"request_id", requestID(ctx),
+		"code", status.Code(err).String(),
+		"duration", dur,
+		"error", err,
+	}
+	if isEndpointFailure(ctx, err) {
+		log.Error("查询失败", kv...)
+		return
+	}
+	log.Warn("查询失败", kv...)
+}
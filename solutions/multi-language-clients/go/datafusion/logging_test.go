@@ -0,0 +1,127 @@
+package datafusion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// logCapture 以 JSON 记录 Debug 及以上级别的 slog 日志
+type logCapture struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *logCapture) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *logCapture) logger() Logger {
+	return NewSlogLogger(slog.New(slog.NewJSONHandler(l, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+// records 解析已记录的日志，每条为键值映射
+func (l *logCapture) records(t *testing.T) []map[string]any {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(l.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("解析日志 %q 失败: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestLoggerRecordsRetryAttempts(t *testing.T) {
+	logs := &logCapture{}
+	srv := &flakyServer{failures: 2, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond), WithLogger(logs.logger()))
+
+	const sql = "SELECT secret_column FROM accounts"
+	resp, err := c.ExecuteQuery(context.Background(), sql)
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	var attempts []float64
+	var finished map[string]any
+	for _, rec := range logs.records(t) {
+		switch rec["msg"] {
+		case "请求失败，稍后重试":
+			if rec["level"] != "WARN" || rec["request_id"] != resp.RequestID || rec["code"] != "Unavailable" {
+				t.Errorf("重试日志 = %v, want WARN、请求 ID %s、code Unavailable", rec, resp.RequestID)
+			}
+			attempts = append(attempts, rec["attempt"].(float64))
+		case "查询结束":
+			finished = rec
+		}
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("重试日志的 attempt = %v, want [1 2]", attempts)
+	}
+	if finished == nil || finished["level"] != "DEBUG" || finished["sql"] != sql {
+		t.Errorf("查询结束日志 = %v, want Debug 级别且包含 SQL", finished)
+	}
+}
+
+func TestLoggerHidesSQLAboveDebug(t *testing.T) {
+	logs := &logCapture{}
+	srv := &flakyServer{failures: 10, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(2, time.Millisecond), WithLogger(logs.logger()))
+
+	const sql = "SELECT secret_column FROM accounts"
+	if _, err := c.ExecuteQuery(context.Background(), sql); err == nil {
+		t.Fatal("重试耗尽后 ExecuteQuery 应返回错误")
+	}
+
+	var failed map[string]any
+	logs.mu.Lock()
+	raw := logs.buf.String()
+	logs.mu.Unlock()
+	for _, rec := range logs.records(t) {
+		if rec["level"] == "DEBUG" {
+			continue
+		}
+		if _, ok := rec["sql"]; ok {
+			t.Errorf("%s 级别日志不应包含 sql: %v", rec["level"], rec)
+		}
+		if rec["msg"] == "查询失败" {
+			failed = rec
+		}
+	}
+	if failed == nil {
+		t.Fatalf("缺少查询失败日志: %s", raw)
+	}
+	if failed["level"] != "ERROR" || failed["sql_hash"] != sqlHash(sql) {
+		t.Errorf("查询失败日志 = %v, want ERROR 级别且 sql_hash = %s", failed, sqlHash(sql))
+	}
+	// 原文只能出现在 Debug 日志中
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.Contains(line, "secret_column") && !strings.Contains(line, `"level":"DEBUG"`) {
+			t.Errorf("非 Debug 日志包含 SQL 原文: %s", line)
+		}
+	}
+}
+
+func TestWithLoggerNil(t *testing.T) {
+	c := newTestClient(t, (&flakyServer{}).fake(), WithLogger(nil))
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+}
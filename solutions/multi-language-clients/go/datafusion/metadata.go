@@ -0,0 +1,124 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tenantHeader 是携带租户的元数据键，服务端据此做隔离和配额
+const tenantHeader = "x-tenant-id"
+
+// ErrInvalidMetadata 表示元数据的键或值不合法，调用未发送。
+var ErrInvalidMetadata = errors.New("无效的元数据")
+
+// gRPC 元数据键只允许小写字母、数字和 - _ .
+var metadataKeyPattern = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// WithTenant 为每次调用附加 x-tenant-id 元数据。
+// 单次调用通过 WithQueryMetadata 指定了 x-tenant-id 时以后者为准。
+func WithTenant(tenant string) Option {
+	return func(o *options) {
+		o.tenant = tenant
+	}
+}
+
+// QueryOption 配置单次查询。
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	metadata map[string]string
+	// statementTimeout 为 nil 表示沿用客户端的 WithStatementTimeout
+	statementTimeout *time.Duration
+}
+
+// WithQueryMetadata 为本次调用附加 gRPC 元数据，如 x-tenant-id。
+// 键必须是小写且不能以保留的 grpc- 开头，否则调用返回 ErrInvalidMetadata。
+// 多次指定时合并，同名键以后者为准。
+func WithQueryMetadata(md map[string]string) QueryOption {
+	return func(o *queryOptions) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]string, len(md))
+		}
+		for k, v := range md {
+			o.metadata[k] = v
+		}
+	}
+}
+
+// applyQueryOptions 校验单次调用的选项并将元数据附加到 ctx
+func applyQueryOptions(ctx context.Context, opts []QueryOption) (context.Context, error) {
+	if len(opts) == 0 {
+		return ctx, nil
+	}
+	var o queryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	keys := make([]string, 0, len(o.metadata))
+	for k := range o.metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kv := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		if err := validateMetadata(k, o.metadata[k]); err != nil {
+			return nil, err
+		}
+		kv = append(kv, k, o.metadata[k])
+	}
+	if o.statementTimeout != nil {
+		kv = append(kv, statementTimeoutHeader, statementTimeoutMillis(*o.statementTimeout))
+	}
+	if len(kv) == 0 {
+		return ctx, nil
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...), nil
+}
+
+func validateMetadata(key, value string) error {
+	if !metadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: 键 %q 只能包含小写字母、数字和 - _ .", ErrInvalidMetadata, key)
+	}
+	if strings.HasPrefix(key, "grpc-") {
+		return fmt.Errorf("%w: 键 %q 使用了保留的 grpc- 前缀", ErrInvalidMetadata, key)
+	}
+	// 以 -bin 结尾的键按二进制发送，值不受限制
+	if strings.HasSuffix(key, "-bin") {
+		return nil
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			return fmt.Errorf("%w: 键 %q 的值包含不可打印字符", ErrInvalidMetadata, key)
+		}
+	}
+	return nil
+}
+
+// withDefaultMetadata 在调用未自带 key 时附加客户端级别的默认值
+func withDefaultMetadata(ctx context.Context, key, value string) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, key, value)
+}
+
+// defaultMetadataInterceptors 返回为每次调用附加默认元数据的拦截器
+func defaultMetadataInterceptors(key, value string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(withDefaultMetadata(ctx, key, value), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(withDefaultMetadata(ctx, key, value), desc, cc, method, opts...)
+		}),
+	}
+}
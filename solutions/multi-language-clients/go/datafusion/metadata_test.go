@@ -0,0 +1,140 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// metadataServer 记录每次调用 (包括结果流) 收到的 key 元数据
+type metadataServer struct {
+	key string
+
+	mu       sync.Mutex
+	received [][]string
+}
+
+func (s *metadataServer) record(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.received = append(s.received, md.Get(s.key))
+	s.mu.Unlock()
+}
+
+func (s *metadataServer) snapshot() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.received...)
+}
+
+func (s *metadataServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			s.record(ctx)
+			return &pb.QueryResponse{}, nil
+		},
+		queryStream: func(_ *pb.QueryRequest, stream pb.DataFusion_QueryStreamServer) error {
+			s.record(stream.Context())
+			return nil
+		},
+	}
+}
+
+// drainStream 读完结果流
+func drainStream(t *testing.T, s *ResultStream) {
+	t.Helper()
+	defer s.Close()
+	for {
+		if _, err := s.Next(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+}
+
+func TestWithTenantSendsHeader(t *testing.T) {
+	srv := &metadataServer{key: tenantHeader}
+	c := newTestClient(t, srv.fake(), WithTenant("acme"))
+	ctx := context.Background()
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	stream, err := c.ExecuteQueryStream(ctx, "SELECT 2")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	drainStream(t, stream)
+	// 单次调用指定的租户优先于客户端默认值，且不会重复发送
+	if _, err := c.ExecuteQuery(ctx, "SELECT 3", WithQueryMetadata(map[string]string{tenantHeader: "globex"})); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	got := srv.snapshot()
+	want := []string{"acme", "acme", "globex"}
+	if len(got) != len(want) {
+		t.Fatalf("服务端收到 %d 次调用, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != 1 || got[i][0] != want[i] {
+			t.Errorf("第 %d 次调用的 %s = %q, want [%s]", i+1, tenantHeader, got[i], want[i])
+		}
+	}
+}
+
+func TestWithoutTenantSendsNoHeader(t *testing.T) {
+	srv := &metadataServer{key: tenantHeader}
+	c := newTestClient(t, srv.fake())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got := srv.snapshot(); len(got) != 1 || len(got[0]) != 0 {
+		t.Errorf("未设置租户时服务端收到 %s = %q", tenantHeader, got)
+	}
+}
+
+func TestWithTenantInvalidValue(t *testing.T) {
+	c, err := NewClient(context.Background(), "127.0.0.1:1", WithInsecure(), WithTenant("acme\n"))
+	if !errors.Is(err, ErrInvalidMetadata) {
+		if c != nil {
+			c.Close()
+		}
+		t.Errorf("NewClient = %v, want ErrInvalidMetadata", err)
+	}
+}
+
+func TestQueryMetadataRejectsInvalidKeys(t *testing.T) {
+	srv := &metadataServer{key: tenantHeader}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	for _, key := range []string{"X-Tenant-Id", "tenant id", "tenant:id", "租户", "", "grpc-timeout", "grpc-custom"} {
+		md := WithQueryMetadata(map[string]string{key: "v"})
+		if _, err := c.ExecuteQuery(ctx, "SELECT 1", md); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("键 %q: ExecuteQuery = %v, want ErrInvalidMetadata", key, err)
+		}
+		if _, err := c.ExecuteQueryStream(ctx, "SELECT 1", md); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("键 %q: ExecuteQueryStream = %v, want ErrInvalidMetadata", key, err)
+		}
+	}
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1", WithQueryMetadata(map[string]string{"x-trace": "a\tb"})); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("值包含不可打印字符: ExecuteQuery = %v, want ErrInvalidMetadata", err)
+	}
+	if got := srv.snapshot(); len(got) != 0 {
+		t.Errorf("无效的元数据不应发往服务端，服务端收到 %d 次调用", len(got))
+	}
+
+	// 合法的键，包括值不受限制的 -bin 键
+	valid := WithQueryMetadata(map[string]string{"x-tenant-id": "acme", "x_a.b-c": "1", "x-blob-bin": "\x00\xff"})
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1", valid); err != nil {
+		t.Errorf("合法元数据: ExecuteQuery = %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// 查询方式标签取值
+const (
+	methodUnary  = "unary"
+	methodStream = "stream"
+	methodMulti  = "multi"
+)
+
+// Metrics 是客户端导出的 Prometheus 指标。
+// 查询指标覆盖与 Hooks 相同的调用，method 标签为 unary、stream 或 multi。
+type Metrics struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	// cancellations 按发起方统计被取消或超时的查询
+	cancellations *prometheus.CounterVec
+
+	uncompressedBytes *prometheus.CounterVec
+	compressedBytes   *prometheus.CounterVec
+
+	stateChanges *prometheus.CounterVec
+	reconnects   prometheus.Counter
+	throttled    prometheus.Counter
+}
+
+// WithMetrics 将客户端指标注册到 reg。
+// 多个客户端共享同一个 reg 时复用已注册的指标。
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = reg
+	}
+}
+
+// NewMetrics 创建指标并注册到 reg。
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_queries_total",
+			Help: "已执行的查询总数",
+		}, []string{"method"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "datafusion_query_duration_seconds",
+			Help:    "查询耗时，流式查询按整个流的读取时间计算",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_query_errors_total",
+			Help: "失败的查询总数，按 gRPC 状态码分类",
+		}, []string{"code"}),
+		cancellations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_query_cancellations_total",
+			Help: "被取消或超时的查询总数，origin 为 client 表示本地上下文结束，server 表示服务端中止",
+		}, []string{"origin"}),
+		uncompressedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_payload_uncompressed_bytes_total",
+			Help: "消息体压缩前的字节数",
+		}, []string{"direction"}),
+		compressedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_payload_compressed_bytes_total",
+			Help: "消息体压缩后的字节数，未压缩时与压缩前相同",
+		}, []string{"direction"}),
+		stateChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "datafusion_connection_state_changes_total",
+			Help: "连接进入各状态的次数",
+		}, []string{"state"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "datafusion_reconnects_total",
+			Help: "连接断开后发起重连的次数",
+		}),
+		throttled: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "datafusion_throttled_requests_total",
+			Help: "因客户端限流而等待或被拒绝的请求数",
+		}),
+	}
+
+	var err error
+	if m.queries, err = register(reg, m.queries); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(reg, m.duration); err != nil {
+		return nil, err
+	}
+	if m.errors, err = register(reg, m.errors); err != nil {
+		return nil, err
+	}
+	if m.cancellations, err = register(reg, m.cancellations); err != nil {
+		return nil, err
+	}
+	if m.uncompressedBytes, err = register(reg, m.uncompressedBytes); err != nil {
+		return nil, err
+	}
+	if m.compressedBytes, err = register(reg, m.compressedBytes); err != nil {
+		return nil, err
+	}
+	if m.stateChanges, err = register(reg, m.stateChanges); err != nil {
+		return nil, err
+	}
+	if m.reconnects, err = register(reg, m.reconnects); err != nil {
+		return nil, err
+	}
+	if m.throttled, err = register(reg, m.throttled); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register 注册 c，已存在同名指标时返回已注册的实例
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) (C, error) {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// observe 记录一次已完成的查询，m 为 nil 时不做任何事。
+// ctx 是发起查询时的上下文，用于判断中止的发起方
+func (m *Metrics) observe(ctx context.Context, method string, err error, dur time.Duration) {
+	if m == nil {
+		return
+	}
+	m.queries.WithLabelValues(method).Inc()
+	m.duration.WithLabelValues(method).Observe(dur.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(status.Code(err).String()).Inc()
+	}
+	if origin := abortOrigin(ctx, err); origin != "" {
+		m.cancellations.WithLabelValues(origin).Inc()
+	}
+}
+
+// 中止发起方标签取值
+const (
+	originClient = "client"
+	originServer = "server"
+)
+
+// abortOrigin 判断查询是被本地上下文还是被服务端中止的，未中止时返回空串
+func abortOrigin(ctx context.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+	if isClientAbort(ctx, err) {
+		return originClient
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return originServer
+	}
+	return ""
+}
+
+// isClientAbort 判断查询是否由调用方中止：上下文已结束，或结果流未读完就被关闭
+func isClientAbort(ctx context.Context, err error) bool {
+	return err != nil && (ctx.Err() != nil || errors.Is(err, ErrStreamClosed))
+}
+
+// observeState 记录一次连接状态变化
+func (m *Metrics) observeState(state connectivity.State) {
+	if m == nil {
+		return
+	}
+	m.stateChanges.WithLabelValues(state.String()).Inc()
+}
+
+// observeReconnect 记录一次重连尝试
+func (m *Metrics) observeReconnect() {
+	if m == nil {
+		return
+	}
+	m.reconnects.Inc()
+}
+
+// observeThrottled 记录一次被限流的请求
+func (m *Metrics) observeThrottled() {
+	if m == nil {
+		return
+	}
+	m.throttled.Inc()
+}
+
+// payloadHandler 是统计消息体字节数的 gRPC stats.Handler
+type payloadHandler struct {
+	m *Metrics
+}
+
+func (h payloadHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h payloadHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch p := s.(type) {
+	case *stats.InPayload:
+		h.observe("received", p.Length, p.CompressedLength)
+	case *stats.OutPayload:
+		h.observe("sent", p.Length, p.CompressedLength)
+	}
+}
+
+func (h payloadHandler) observe(direction string, uncompressed, compressed int) {
+	h.m.uncompressedBytes.WithLabelValues(direction).Add(float64(uncompressed))
+	h.m.compressedBytes.WithLabelValues(direction).Add(float64(compressed))
+}
+
+func (h payloadHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h payloadHandler) HandleConn(context.Context, stats.ConnStats) {}
@@ -0,0 +1,158 @@
+package datafusion
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// statusServer 对每次查询返回固定的状态码
+type statusServer struct {
+	code codes.Code
+}
+
+func (s *statusServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *statusServer) executeQuery(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if s.code == codes.OK {
+		return &pb.QueryResponse{}, nil
+	}
+	return nil, status.Error(s.code, "服务端中止")
+}
+
+// cancellations 返回各中止发起方的计数
+func cancellations(m *Metrics) (client, server float64) {
+	return testutil.ToFloat64(m.cancellations.WithLabelValues(originClient)),
+		testutil.ToFloat64(m.cancellations.WithLabelValues(originServer))
+}
+
+func TestMetricsClientCancel(t *testing.T) {
+	srv := newSlowServer()
+	defer close(srv.release)
+	c := newTestClient(t, srv.fake(), WithMetrics(prometheus.NewRegistry()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-srv.started
+		cancel()
+	}()
+	if _, err := c.ExecuteQuery(ctx, "SELECT slow"); status.Code(err) != codes.Canceled {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+
+	client, server := cancellations(c.metrics)
+	if client != 1 || server != 0 {
+		t.Errorf("cancellations client=%v server=%v, want 1/0", client, server)
+	}
+	if got := testutil.ToFloat64(c.metrics.errors.WithLabelValues(codes.Canceled.String())); got != 1 {
+		t.Errorf("errors{code=Canceled} = %v, want 1", got)
+	}
+}
+
+func TestMetricsServerAbort(t *testing.T) {
+	for _, code := range []codes.Code{codes.Canceled, codes.DeadlineExceeded} {
+		t.Run(code.String(), func(t *testing.T) {
+			c := newTestClient(t, (&statusServer{code: code}).fake(), WithMetrics(prometheus.NewRegistry()))
+
+			if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); status.Code(err) != code {
+				t.Fatalf("err = %v, want %v", err, code)
+			}
+			client, server := cancellations(c.metrics)
+			if client != 0 || server != 1 {
+				t.Errorf("cancellations client=%v server=%v, want 0/1", client, server)
+			}
+		})
+	}
+}
+
+func TestMetricsOtherErrorsAreNotCancellations(t *testing.T) {
+	c := newTestClient(t, (&statusServer{code: codes.InvalidArgument}).fake(), WithMetrics(prometheus.NewRegistry()))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if got := testutil.CollectAndCount(c.metrics.cancellations); got != 0 {
+		t.Errorf("cancellations 序列数 = %d, want 0", got)
+	}
+	if got := testutil.ToFloat64(c.metrics.queries.WithLabelValues(methodUnary)); got != 1 {
+		t.Errorf("queries{method=unary} = %v, want 1", got)
+	}
+}
+
+func TestMetricsSharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	srv := &statusServer{}
+	a := newTestClient(t, srv.fake(), WithMetrics(reg))
+	b := newTestClient(t, srv.fake(), WithMetrics(reg))
+
+	for _, c := range []*DataFusionClient{a, b} {
+		if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := testutil.ToFloat64(a.metrics.queries.WithLabelValues(methodUnary)); got != 2 {
+		t.Errorf("共享注册表的 queries = %v, want 2", got)
+	}
+}
+
+func TestMetricsScrapeAfterSuccessAndFailure(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	// 首次调用返回不可重试的 InvalidArgument，之后成功
+	srv := &flakyServer{failures: 1, code: codes.InvalidArgument}
+	c := newTestClient(t, srv.fake(), WithMetrics(reg))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("首次查询 err = %v, want InvalidArgument", err)
+	}
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("第二次查询: %v", err)
+	}
+
+	want := `
+# HELP datafusion_queries_total 已执行的查询总数
+# TYPE datafusion_queries_total counter
+datafusion_queries_total{method="unary"} 2
+# HELP datafusion_query_errors_total 失败的查询总数，按 gRPC 状态码分类
+# TYPE datafusion_query_errors_total counter
+datafusion_query_errors_total{code="InvalidArgument"} 1
+`
+	// 成功的查询不计入错误，errors_total 只有一个序列
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"datafusion_queries_total", "datafusion_query_errors_total"); err != nil {
+		t.Error(err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var found bool
+	for _, mf := range families {
+		if mf.GetName() != "datafusion_query_duration_seconds" {
+			continue
+		}
+		found = true
+		h := mf.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 2 {
+			t.Errorf("耗时直方图样本数 = %d, want 2", h.GetSampleCount())
+		}
+		if sum := time.Duration(h.GetSampleSum() * float64(time.Second)); sum <= 0 || sum > 5*time.Second {
+			t.Errorf("耗时直方图总和 = %v", sum)
+		}
+	}
+	if !found {
+		t.Error("抓取结果中没有 datafusion_query_duration_seconds")
+	}
+}
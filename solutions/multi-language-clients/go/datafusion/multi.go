@@ -0,0 +1,125 @@
+package datafusion
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// MultiResult 按语句顺序迭代多语句查询的结果。
+// 读取完毕或不再需要时应调用 Close。
+type MultiResult struct {
+	sql    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	stream pb.DataFusion_ExecuteMultiClient
+	// queryID 是响应头中的查询 ID，首条结果到达时读取
+	queryID string
+	// rows 是各语句结果集的总行数
+	rows  int
+	ended bool
+	// finished 在结果读完或被关闭时关闭
+	finished chan struct{}
+	onEnd    func(err error)
+}
+
+// ExecuteMulti 执行以分号分隔的多条语句，如 "SELECT 1; INSERT INTO t VALUES (1)"。
+// 服务端按顺序执行并逐条返回结果，某条语句失败时其后的语句不再执行。
+// 不产生结果集的语句 (如 DDL、INSERT) 的影响行数通过 QueryResponse.RowsAffected 获取。
+//
+// 整个脚本按一次查询记录指标、日志和 Hooks，并登记为进行中的查询，
+// 调用方上下文结束或 CancelAll 时会通知服务端终止。
+func (c *DataFusionClient) ExecuteMulti(ctx context.Context, sql string) (*MultiResult, error) {
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = ensureRequestID(ctx)
+	parent := ctx
+	c.opts.hooks.queryStart(parent, sql)
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	active, remove := c.active.add(cancel)
+	end := func(err error, rows int) {
+		remove()
+		dur := time.Since(start)
+		c.metrics.observe(parent, methodMulti, err, dur)
+		c.logQuery(parent, methodMulti, sql, err, dur)
+		c.opts.hooks.queryEnd(parent, sql, rows, err, dur)
+		done()
+	}
+	stream, err := c.rpc.ExecuteMulti(withActiveQuery(ctx, active), &pb.QueryRequest{Sql: sql})
+	if err != nil {
+		cancel()
+		err = newQueryError(ctx, sql, err)
+		end(err, 0)
+		return nil, err
+	}
+
+	m := &MultiResult{sql: sql, ctx: ctx, cancel: cancel, stream: stream, finished: make(chan struct{})}
+	m.onEnd = func(err error) { end(err, m.rows) }
+	go func() {
+		select {
+		case <-parent.Done():
+		case <-m.finished:
+			// NextResult 因调用方上下文结束而返回时结果已结束，仍需通知服务端
+			if parent.Err() == nil {
+				return
+			}
+		}
+		if header, err := stream.Header(); err == nil {
+			if id, ok := active.claimCancel(queryIDFromHeader(header)); ok {
+				c.cancelOnServer(id)
+			}
+		}
+	}()
+	return m, nil
+}
+
+// NextResult 返回下一条语句的结果，所有语句都已返回时返回 io.EOF，
+// 语句失败时返回 *QueryError，之后的调用都返回 io.EOF。
+func (m *MultiResult) NextResult() (*QueryResponse, error) {
+	if m.ended {
+		return nil, io.EOF
+	}
+	resp, err := m.stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			m.finish(nil)
+			return nil, io.EOF
+		}
+		err = newQueryError(m.ctx, m.sql, err)
+		m.finish(err)
+		return nil, err
+	}
+	if m.queryID == "" {
+		if header, err := m.stream.Header(); err == nil {
+			m.queryID = queryIDFromHeader(header)
+		}
+	}
+	m.rows += len(resp.GetRows())
+	return newQueryResponse(m.ctx, resp, m.queryID), nil
+}
+
+// Close 终止尚未读取的结果。未读到 io.EOF 就关闭时按 ErrStreamClosed 记录。
+func (m *MultiResult) Close() error {
+	m.finish(ErrStreamClosed)
+	return nil
+}
+
+// finish 结束调用并以 err 执行一次 onEnd
+func (m *MultiResult) finish(err error) {
+	if m.ended {
+		return
+	}
+	m.ended = true
+	close(m.finished)
+	m.cancel()
+	m.onEnd(err)
+}
@@ -0,0 +1,243 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+const multiScript = "SELECT id FROM users; INSERT INTO audit VALUES (1)"
+
+// multiServer 对脚本返回一个两行的结果集和一条 INSERT 的影响行数。
+// hold 为 true 时在发送首条结果后阻塞，直到调用结束
+type multiServer struct {
+	hold     bool
+	started  chan struct{}
+	canceled chan string
+}
+
+func (s *multiServer) fake() *fakeServer {
+	return &fakeServer{
+		executeMulti: s.executeMulti,
+		cancelQuery:  s.cancelQuery,
+	}
+}
+
+func newMultiServer(hold bool) *multiServer {
+	return &multiServer{hold: hold, started: make(chan struct{}, 1), canceled: make(chan string, 4)}
+}
+
+func (s *multiServer) executeMulti(req *pb.QueryRequest, stream pb.DataFusion_ExecuteMultiServer) error {
+	if err := stream.SendHeader(metadata.Pairs("x-query-id", "m-1")); err != nil {
+		return err
+	}
+	err := stream.Send(&pb.QueryResponse{
+		Columns: []*pb.Column{{Name: "id", DataType: "Int64"}},
+		Rows: []*pb.Row{
+			{Values: []*pb.Value{mustPBValue(int64(1))}},
+			{Values: []*pb.Value{mustPBValue(int64(2))}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if s.hold {
+		s.started <- struct{}{}
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	affected := int64(1)
+	return stream.Send(&pb.QueryResponse{RowsAffected: &affected})
+}
+
+func (s *multiServer) cancelQuery(_ context.Context, req *pb.CancelQueryRequest) (*pb.CancelQueryResponse, error) {
+	s.canceled <- req.GetQueryId()
+	return &pb.CancelQueryResponse{}, nil
+}
+
+// warnLogger 统计 Warn 和 Error 日志
+type warnLogger struct {
+	nopLogger
+	mu    sync.Mutex
+	warns int
+}
+
+func (l *warnLogger) Warn(string, ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns++
+}
+
+func (l *warnLogger) Error(msg string, kv ...any) { l.Warn(msg, kv...) }
+
+func (l *warnLogger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.warns
+}
+
+func TestExecuteMultiSelectThenInsert(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	c := newTestClient(t, newMultiServer(false).fake(), WithHooks(rec.hooks()), WithMetrics(prometheus.NewRegistry()))
+
+	m, err := c.ExecuteMulti(context.Background(), multiScript)
+	if err != nil {
+		t.Fatalf("ExecuteMulti: %v", err)
+	}
+	if n := len(c.active.snapshot()); n != 1 {
+		t.Errorf("执行中登记了 %d 个查询, want 1", n)
+	}
+
+	sel, err := m.NextResult()
+	if err != nil {
+		t.Fatalf("SELECT 结果: %v", err)
+	}
+	if _, ok := sel.RowsAffected(); ok || len(sel.Rows) != 2 || sel.Rows[1][0] != int64(2) || sel.QueryID != "m-1" {
+		t.Errorf("SELECT 结果 = %+v", sel)
+	}
+	ins, err := m.NextResult()
+	if err != nil {
+		t.Fatalf("INSERT 结果: %v", err)
+	}
+	if n, ok := ins.RowsAffected(); !ok || n != 1 || len(ins.Rows) != 0 || ins.QueryID != "m-1" {
+		t.Errorf("INSERT 结果 = %+v", ins)
+	}
+	if sel.RequestID == "" || ins.RequestID != sel.RequestID {
+		t.Errorf("RequestID = %q / %q, want 脚本内相同", sel.RequestID, ins.RequestID)
+	}
+	if _, err := m.NextResult(); err != io.EOF {
+		t.Fatalf("读完后 err = %v, want io.EOF", err)
+	}
+	m.Close()
+
+	starts, ends := rec.snapshot()
+	if len(starts) != 1 || starts[0] != multiScript {
+		t.Errorf("OnQueryStart = %q, want 整个脚本一次", starts)
+	}
+	if len(ends) != 1 || ends[0] != (queryEndRecord{sql: multiScript, rows: 2}) {
+		t.Errorf("OnQueryEnd = %+v, want 一次且 rows=2", ends)
+	}
+	if got := testutil.ToFloat64(c.metrics.queries.WithLabelValues(methodMulti)); got != 1 {
+		t.Errorf("queries{method=multi} = %v, want 1", got)
+	}
+	if n := len(c.active.snapshot()); n != 0 {
+		t.Errorf("结束后仍登记了 %d 个查询", n)
+	}
+}
+
+func TestExecuteMultiCloseEarly(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	log := &warnLogger{}
+	c := newTestClient(t, newMultiServer(true).fake(), WithHooks(rec.hooks()), WithLogger(log), WithMetrics(prometheus.NewRegistry()))
+
+	m, err := c.ExecuteMulti(context.Background(), multiScript)
+	if err != nil {
+		t.Fatalf("ExecuteMulti: %v", err)
+	}
+	if _, err := m.NextResult(); err != nil {
+		t.Fatalf("NextResult: %v", err)
+	}
+	m.Close()
+	m.Close()
+
+	_, ends := rec.snapshot()
+	if len(ends) != 1 || ends[0].rows != 2 || !errors.Is(ends[0].err, ErrStreamClosed) {
+		t.Errorf("OnQueryEnd = %+v, want 一次且 err=ErrStreamClosed", ends)
+	}
+	if n := log.count(); n != 0 {
+		t.Errorf("提前关闭输出了 %d 条失败日志", n)
+	}
+	client, _ := cancellations(c.metrics)
+	if client != 1 {
+		t.Errorf("cancellations{origin=client} = %v, want 1", client)
+	}
+}
+
+func TestExecuteMultiCancelAll(t *testing.T) {
+	srv := newMultiServer(true)
+	c := newTestClient(t, srv.fake())
+
+	m, err := c.ExecuteMulti(context.Background(), multiScript)
+	if err != nil {
+		t.Fatalf("ExecuteMulti: %v", err)
+	}
+	defer m.Close()
+	if _, err := m.NextResult(); err != nil {
+		t.Fatalf("NextResult: %v", err)
+	}
+	<-srv.started
+	waitQueryIDs(t, c, 1)
+
+	if err := c.CancelAll(context.Background()); err != nil {
+		t.Fatalf("CancelAll: %v", err)
+	}
+	if _, err := m.NextResult(); status.Code(err) != codes.Canceled {
+		t.Errorf("NextResult err = %v, want Canceled", err)
+	}
+	select {
+	case id := <-srv.canceled:
+		if id != "m-1" {
+			t.Errorf("CancelQuery(%q), want m-1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("服务端未收到 CancelQuery")
+	}
+}
+
+func TestExecuteMultiContextCancel(t *testing.T) {
+	srv := newMultiServer(true)
+	c := newTestClient(t, srv.fake())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m, err := c.ExecuteMulti(ctx, multiScript)
+	if err != nil {
+		t.Fatalf("ExecuteMulti: %v", err)
+	}
+	defer m.Close()
+	<-srv.started
+	waitQueryIDs(t, c, 1)
+	cancel()
+
+	for {
+		if _, err := m.NextResult(); err != nil {
+			if status.Code(err) != codes.Canceled {
+				t.Errorf("err = %v, want Canceled", err)
+			}
+			break
+		}
+	}
+	select {
+	case id := <-srv.canceled:
+		if id != "m-1" {
+			t.Errorf("CancelQuery(%q), want m-1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("服务端未收到 CancelQuery")
+	}
+}
+
+func TestExecuteMultiShutdownWaits(t *testing.T) {
+	c := newTestClient(t, newMultiServer(true).fake())
+	m, err := c.ExecuteMulti(context.Background(), multiScript)
+	if err != nil {
+		t.Fatalf("ExecuteMulti: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown err = %v, want 等待未关闭的结果直到超时", err)
+	}
+	m.Close()
+}
@@ -0,0 +1,65 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamJSON 以流式方式执行查询，每收到一个批次就把其中的行
+// 以 NDJSON (每行一个 JSON 对象) 写入 w，下游无需等待查询结束。
+// 写入 w 失败时终止服务端的结果流并返回写入错误。
+func (c *DataFusionClient) StreamJSON(ctx context.Context, sql string, w io.Writer) error {
+	s, err := c.ExecuteQueryStream(ctx, sql)
+	if err != nil {
+		return err
+	}
+	return WriteNDJSON(w, s)
+}
+
+// StreamJSON 在下一个可用端点上执行 StreamJSON。
+func (p *Pool) StreamJSON(ctx context.Context, sql string, w io.Writer) error {
+	s, err := p.ExecuteQueryStream(ctx, sql)
+	if err != nil {
+		return err
+	}
+	return WriteNDJSON(w, s)
+}
+
+// WriteNDJSON 读完 s 并将每行以 NDJSON 写入 w，结束后关闭 s。
+// w 实现了 Flush 时每个批次写完后都会刷新。
+func WriteNDJSON(w io.Writer, s *ResultStream) error {
+	defer s.Close()
+	for {
+		batch, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		b, err := ndjsonLines(batch.Columns, batch.Rows)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("写入结果失败: %w", err)
+		}
+		if err := flush(w); err != nil {
+			return fmt.Errorf("写入结果失败: %w", err)
+		}
+	}
+}
+
+// flush 刷新带缓冲的 writer，如 *bufio.Writer 或 http.ResponseWriter
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case http.Flusher:
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package datafusion
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// streamServer 以多个批次返回 rows 行，每批 batchSize 行；
+// hold 为 true 时发完后阻塞到调用方取消
+type streamServer struct {
+	rows      int
+	batchSize int
+	hold      bool
+}
+
+func (s *streamServer) fake() *fakeServer {
+	return &fakeServer{
+		queryStream: s.queryStream,
+	}
+}
+
+func (s *streamServer) queryStream(req *pb.QueryRequest, stream pb.DataFusion_QueryStreamServer) error {
+	cols := []*pb.Column{{Name: "id", DataType: "Int64"}, {Name: "name", DataType: "Utf8", Nullable: true}}
+	for sent := 0; sent < s.rows; {
+		batch := &pb.RowBatch{}
+		if sent == 0 {
+			batch.Columns = cols
+		}
+		for i := 0; i < s.batchSize && sent < s.rows; i++ {
+			var name any = "row"
+			if sent%3 == 0 {
+				name = nil
+			}
+			batch.Rows = append(batch.Rows, &pb.Row{Values: []*pb.Value{
+				{Kind: &pb.Value_IntValue{IntValue: int64(sent)}},
+				mustPBValue(name),
+			}})
+			sent++
+		}
+		if err := stream.Send(batch); err != nil {
+			return err
+		}
+	}
+	if s.hold {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	return nil
+}
+
+// endRecorder 记录 OnQueryEnd 收到的错误
+type endRecorder struct {
+	mu   sync.Mutex
+	errs []error
+	rows []int
+}
+
+func (r *endRecorder) hooks() Hooks {
+	return Hooks{OnQueryEnd: func(ctx context.Context, sql string, rows int, err error, dur time.Duration) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.errs = append(r.errs, err)
+		r.rows = append(r.rows, rows)
+	}}
+}
+
+func (r *endRecorder) only(t *testing.T) (error, int) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.errs) != 1 {
+		t.Fatalf("OnQueryEnd 调用了 %d 次, want 1", len(r.errs))
+	}
+	return r.errs[0], r.rows[0]
+}
+
+func TestStreamJSONWritesOneLinePerRow(t *testing.T) {
+	const n = 10
+	rec := &endRecorder{}
+	c := newTestClient(t, (&streamServer{rows: n, batchSize: 3}).fake(), WithHooks(rec.hooks()))
+
+	var buf bytes.Buffer
+	if err := c.StreamJSON(context.Background(), "SELECT id, name FROM t", &buf); err != nil {
+		t.Fatalf("StreamJSON: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != n || !strings.HasSuffix(buf.String(), "\n") {
+		t.Fatalf("输出 %d 行, want %d:\n%s", len(lines), n, buf.String())
+	}
+	for i, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("第 %d 行不是合法 JSON: %q: %v", i+1, line, err)
+		}
+		if obj["id"] != float64(i) {
+			t.Errorf("第 %d 行 id = %v", i+1, obj["id"])
+		}
+		if name, ok := obj["name"]; !ok || (i%3 == 0) != (name == nil) {
+			t.Errorf("第 %d 行 name = %v", i+1, name)
+		}
+	}
+	if err, rows := rec.only(t); err != nil || rows != n {
+		t.Errorf("OnQueryEnd err = %v, rows = %d", err, rows)
+	}
+}
+
+// failingWriter 在写入 ok 次后失败
+type failingWriter struct {
+	ok int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.ok == 0 {
+		return 0, errors.New("管道已关闭")
+	}
+	w.ok--
+	return len(p), nil
+}
+
+func TestStreamJSONWriterErrorAbortsStream(t *testing.T) {
+	rec := &endRecorder{}
+	c := newTestClient(t, (&streamServer{rows: 4, batchSize: 1, hold: true}).fake(),
+		WithHooks(rec.hooks()), WithMetrics(prometheus.NewRegistry()))
+
+	err := c.StreamJSON(context.Background(), "SELECT id, name FROM t", &failingWriter{ok: 1})
+	if err == nil || !strings.Contains(err.Error(), "管道已关闭") {
+		t.Fatalf("err = %v, want 写入错误", err)
+	}
+
+	endErr, _ := rec.only(t)
+	if !errors.Is(endErr, ErrStreamClosed) || status.Code(endErr) != codes.Canceled {
+		t.Errorf("OnQueryEnd err = %v, want ErrStreamClosed", endErr)
+	}
+	client, server := cancellations(c.metrics)
+	if client != 1 || server != 0 {
+		t.Errorf("cancellations client=%v server=%v, want 1/0", client, server)
+	}
+}
+
+func TestWriteNDJSONFlushesEachBatch(t *testing.T) {
+	s := NewResultStream(
+		&RowBatch{Columns: []Column{{Name: "x"}}, Rows: []Row{{int64(1)}}},
+		&RowBatch{Rows: []Row{{int64(2)}, {int64(3)}}},
+	)
+	var buf bytes.Buffer
+	w := &countingFlusher{Writer: bufio.NewWriterSize(&buf, 4096)}
+	if err := WriteNDJSON(w, s); err != nil {
+		t.Fatalf("WriteNDJSON: %v", err)
+	}
+	if buf.String() != "{\"x\":1}\n{\"x\":2}\n{\"x\":3}\n" {
+		t.Errorf("输出 = %q", buf.String())
+	}
+	if w.flushes != 2 {
+		t.Errorf("刷新 %d 次, want 2", w.flushes)
+	}
+}
+
+type countingFlusher struct {
+	*bufio.Writer
+	flushes int
+}
+
+func (w *countingFlusher) Flush() error {
+	w.flushes++
+	return w.Writer.Flush()
+}
+
+func TestStreamCloseBeforeEOFIsRecordedAsCancel(t *testing.T) {
+	rec := &endRecorder{}
+	c := newTestClient(t, (&streamServer{rows: 4, batchSize: 1, hold: true}).fake(),
+		WithHooks(rec.hooks()), WithMetrics(prometheus.NewRegistry()))
+
+	s, err := c.ExecuteQueryStream(context.Background(), "SELECT id, name FROM t")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	if _, err := s.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	s.Close()
+	s.Close()
+
+	endErr, rows := rec.only(t)
+	if !errors.Is(endErr, ErrStreamClosed) || rows != 1 {
+		t.Errorf("OnQueryEnd err = %v, rows = %d", endErr, rows)
+	}
+	if got := testutil.ToFloat64(c.metrics.errors.WithLabelValues(codes.Canceled.String())); got != 1 {
+		t.Errorf("errors{code=Canceled} = %v, want 1", got)
+	}
+}
+
+func TestStreamCloseAfterEOFIsSuccess(t *testing.T) {
+	rec := &endRecorder{}
+	c := newTestClient(t, (&streamServer{rows: 2, batchSize: 2}).fake(), WithHooks(rec.hooks()))
+
+	s, err := c.ExecuteQueryStream(context.Background(), "SELECT id, name FROM t")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	for {
+		if _, err := s.Next(); err != nil {
+			break
+		}
+	}
+	s.Close()
+
+	if endErr, rows := rec.only(t); endErr != nil || rows != 2 {
+		t.Errorf("OnQueryEnd err = %v, rows = %d", endErr, rows)
+	}
+}
@@ -0,0 +1,230 @@
+package datafusion
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// 默认建连超时
+	defaultDialTimeout = 10 * time.Second
+	// 未调用 WithDialTimeout 时单次连接尝试的最短时限，与 gRPC 默认值一致
+	defaultMinConnectTimeout = 20 * time.Second
+	// 默认的最大接收消息大小。gRPC 自身默认 4MB，分析查询的结果很容易超出
+	defaultMaxRecvMsgSize = 64 << 20
+)
+
+// Option 配置 NewClient 创建的客户端。
+type Option func(*options)
+
+type options struct {
+	tlsConfig  *tls.Config
+	tlsFiles   *tlsFiles
+	serverName string
+	insecure   bool
+
+	// tokenSource 为空表示不发送令牌
+	tokenSource         oauth2.TokenSource
+	allowInsecureTokens bool
+
+	dialTimeout time.Duration
+	// dialTimeoutSet 表示调用方通过 WithDialTimeout 设置了建连超时
+	dialTimeoutSet bool
+	blockingDial   bool
+	userAgent      string
+	keepalive      *keepalive.ClientParameters
+	retry          retryPolicy
+	cluster        *clusterConfig
+	staticAddrs    []string
+
+	reconnect bool
+	// reconnectBackoff 为 0 表示使用 gRPC 默认的重连退避上限
+	reconnectBackoff time.Duration
+	reconnectGrace   time.Duration
+
+	breakerFailures int
+	breakerCooldown time.Duration
+	healthCheck     bool
+	healthService   string
+
+	maxRecvMsgSize int
+	maxSendMsgSize int
+	compression    string
+
+	rateLimit    rate.Limit
+	rateBurst    int
+	rateFailFast bool
+
+	queryTimeout     time.Duration
+	statementTimeout time.Duration
+	budgetPolicy     BudgetPolicy
+	validate         bool
+	tenant           string
+
+	sessionDefaults map[string]string
+	cacheSize       int
+	cacheTTL        time.Duration
+
+	logger         Logger
+	hooks          Hooks
+	tracerProvider trace.TracerProvider
+	registerer     prometheus.Registerer
+}
+
+func defaultOptions() options {
+	return options{
+		dialTimeout:    defaultDialTimeout,
+		maxRecvMsgSize: defaultMaxRecvMsgSize,
+		logger:         nopLogger{},
+	}
+}
+
+// WithTLS 使用给定的 TLS 配置连接服务端。
+// 未指定任何传输选项时，默认使用系统根证书的 TLS。
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+		o.tlsFiles = nil
+		o.insecure = false
+	}
+}
+
+// WithInsecure 使用明文连接，仅适用于本地开发。
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+		o.tlsConfig = nil
+		o.tlsFiles = nil
+	}
+}
+
+// WithDialTimeout 设置建连超时，默认 10 秒。
+// 它限制 WithBlockingDial 时 NewClient 的等待时间，
+// 同时作为每次连接尝试 (包括断线重连) 的最短时限；
+// 未调用时单次连接尝试使用 gRPC 默认的 20 秒。
+func WithDialTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = d
+		o.dialTimeoutSet = true
+	}
+}
+
+// WithUserAgent 设置 gRPC user-agent 前缀。
+func WithUserAgent(ua string) Option {
+	return func(o *options) {
+		o.userAgent = ua
+	}
+}
+
+// WithMaxRecvMsgSize 设置可接收的最大消息字节数，默认 64MB。
+// 响应超出限制时查询以 ResourceExhausted 失败。
+func WithMaxRecvMsgSize(n int) Option {
+	return func(o *options) {
+		o.maxRecvMsgSize = n
+	}
+}
+
+// WithMaxSendMsgSize 设置可发送的最大消息字节数，默认不限制。
+func WithMaxSendMsgSize(n int) Option {
+	return func(o *options) {
+		o.maxSendMsgSize = n
+	}
+}
+
+// dialOptions 将配置转换为 gRPC 拨号选项
+func (o *options) dialOptions() ([]grpc.DialOption, error) {
+	creds, err := o.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(requestIDUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(requestIDStreamInterceptor),
+	}
+
+	perRPC, err := o.perRPCCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if perRPC != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(perRPC))
+	}
+	// 种子节点连接使用相同的传输与认证配置
+	seedOpts := append([]grpc.DialOption(nil), dialOpts...)
+
+	var callOpts []grpc.CallOption
+	if o.maxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(o.maxRecvMsgSize))
+	}
+	if o.maxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(o.maxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	if params, ok := o.connectParams(); ok {
+		dialOpts = append(dialOpts, grpc.WithConnectParams(params))
+	}
+
+	if o.userAgent != "" {
+		dialOpts = append(dialOpts, grpc.WithUserAgent(o.userAgent))
+	}
+
+	if o.cluster != nil {
+		dialOpts = append(dialOpts,
+			grpc.WithResolvers(newClusterBuilder(o.cluster, seedOpts...)),
+			grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+		)
+	}
+	if len(o.staticAddrs) > 0 {
+		dialOpts = append(dialOpts,
+			grpc.WithResolvers(newStaticBuilder(o.staticAddrs)),
+			grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+		)
+	}
+
+	if o.keepalive != nil {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(*o.keepalive))
+	}
+
+	if o.tracerProvider != nil {
+		handler := otelgrpc.NewClientHandler(otelClientOptions(o.tracerProvider)...)
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(handler))
+	}
+
+	return dialOpts, nil
+}
+
+// connectParams 返回连接尝试的时限与重连退避，
+// 调用方既没有设置建连超时也没有设置重连退避时返回 false，沿用 gRPC 默认值
+func (o *options) connectParams() (grpc.ConnectParams, bool) {
+	setTimeout := o.dialTimeoutSet && o.dialTimeout > 0
+	if !setTimeout && o.reconnectBackoff <= 0 {
+		return grpc.ConnectParams{}, false
+	}
+	params := grpc.ConnectParams{
+		Backoff:           backoff.DefaultConfig,
+		MinConnectTimeout: defaultMinConnectTimeout,
+	}
+	if setTimeout {
+		params.MinConnectTimeout = o.dialTimeout
+	}
+	if o.reconnectBackoff > 0 {
+		params.Backoff.MaxDelay = o.reconnectBackoff
+		if params.Backoff.BaseDelay > o.reconnectBackoff {
+			params.Backoff.BaseDelay = o.reconnectBackoff
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,66 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// largeRowServer 返回一行包含 size 字节字符串的结果
+func largeRowServer(size int) *fakeServer {
+	return &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{Rows: []*pb.Row{{Values: []*pb.Value{mustPBValue(strings.Repeat("x", size))}}}}, nil
+		},
+	}
+}
+
+func TestWithMaxRecvMsgSizeOverLimit(t *testing.T) {
+	c := newTestClient(t, largeRowServer(8<<10), WithMaxRecvMsgSize(1<<10))
+
+	_, err := c.ExecuteQuery(context.Background(), "SELECT payload FROM blobs")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("ExecuteQuery = %v, want ResourceExhausted", err)
+	}
+	var qe *QueryError
+	if !errors.As(err, &qe) || !strings.Contains(qe.Message, "WithMaxRecvMsgSize") {
+		t.Errorf("错误信息应提示调整 WithMaxRecvMsgSize: %v", err)
+	}
+}
+
+func TestWithMaxRecvMsgSizeWithinLimit(t *testing.T) {
+	c := newTestClient(t, largeRowServer(8<<10), WithMaxRecvMsgSize(16<<10))
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT payload FROM blobs")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(resp.Rows) != 1 {
+		t.Errorf("行数 = %d, want 1", len(resp.Rows))
+	}
+}
+
+func TestWithMaxSendMsgSizeOverLimit(t *testing.T) {
+	var calls int
+	srv := &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			calls++
+			return &pb.QueryResponse{}, nil
+		},
+	}
+	c := newTestClient(t, srv, WithMaxSendMsgSize(1<<10))
+
+	sql := "SELECT '" + strings.Repeat("x", 4<<10) + "'"
+	if _, err := c.ExecuteQuery(context.Background(), sql); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("ExecuteQuery = %v, want ResourceExhausted", err)
+	}
+	if calls != 0 {
+		t.Errorf("超出发送限制的请求不应到达服务端，收到 %d 次", calls)
+	}
+}
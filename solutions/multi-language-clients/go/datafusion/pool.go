@@ -0,0 +1,261 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoHealthyEndpoint 表示连接池中没有可用的端点。
+var ErrNoHealthyEndpoint = errors.New("没有可用的服务端点")
+
+// 端点角色
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// Pool 为每个服务端点维护一个连接，并以轮询方式分发查询。
+// 处于 TRANSIENT_FAILURE 的端点会被跳过，直到其重新连上；
+// 配置了 WithCircuitBreaker 时，被熔断的端点同样会被跳过；
+// 配置了 WithHealthCheck 时，健康检查报告 NOT_SERVING 的端点也会被跳过。
+//
+// 由 NewReadWritePool 创建时，写语句只发往主节点，
+// 读语句在副本间轮询，所有副本都不可用时退回主节点。
+type Pool struct {
+	endpoints []*endpoint
+	// readers 是读语句轮询的端点
+	readers []*endpoint
+	// primary 仅在读写分离模式下非空
+	primary *endpoint
+	next    atomic.Uint64
+
+	// stopWatch 在 Close 时结束健康检查协程，watchers 等待它们退出
+	watchCtx  context.Context
+	stopWatch context.CancelFunc
+	watchers  sync.WaitGroup
+}
+
+func newPool() *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{watchCtx: ctx, stopWatch: cancel}
+}
+
+type endpoint struct {
+	target   string
+	role     string
+	client   *DataFusionClient
+	breaker  *breaker
+	health   atomic.Int32
+	inFlight atomic.Int64
+}
+
+// EndpointStats 是单个端点的运行状态。
+type EndpointStats struct {
+	Target string
+	// Role 是读写分离模式下的角色，否则为空
+	Role    string
+	State   connectivity.State
+	Breaker BreakerState
+	// Health 是健康检查报告的状态，未启用 WithHealthCheck 时为 HealthUnknown
+	Health HealthStatus
+	// Healthy 表示连接可用、未被熔断且健康检查未报告 NOT_SERVING
+	Healthy  bool
+	InFlight int64
+}
+
+// NewPool 为每个 target 建立连接，opts 应用于所有端点。
+func NewPool(targets []string, opts ...Option) (*Pool, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("至少需要一个服务端点")
+	}
+
+	p := newPool()
+	for _, target := range targets {
+		ep, err := p.add(target, "", opts)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.readers = append(p.readers, ep)
+	}
+	return p, nil
+}
+
+// NewReadWritePool 创建读写分离的连接池：写语句发往 primary，读语句发往 replicas。
+func NewReadWritePool(primary string, replicas []string, opts ...Option) (*Pool, error) {
+	p := newPool()
+	ep, err := p.add(primary, RolePrimary, opts)
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	p.primary = ep
+
+	for _, target := range replicas {
+		ep, err := p.add(target, RoleReplica, opts)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.readers = append(p.readers, ep)
+	}
+	return p, nil
+}
+
+func (p *Pool) add(target, role string, opts []Option) (*endpoint, error) {
+	c, err := NewClient(context.Background(), target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ep := &endpoint{target: target, role: role, client: c, breaker: newBreaker(&c.opts)}
+	p.endpoints = append(p.endpoints, ep)
+	if c.opts.healthCheck {
+		p.watchers.Add(1)
+		go func() {
+			defer p.watchers.Done()
+			ep.watchHealth(p.watchCtx, c.opts.healthService)
+		}()
+	}
+	return ep, nil
+}
+
+// ExecuteQuery 在下一个可用端点上执行查询。
+// 只读查询遇到端点不可用时会转移到其他端点，每个端点最多尝试一次。
+func (p *Pool) ExecuteQuery(ctx context.Context, sql string, opts ...QueryOption) (*QueryResponse, error) {
+	// 故障转移时沿用同一请求 ID
+	ctx = ensureRequestID(ctx)
+	tried := make(map[*endpoint]bool, len(p.endpoints))
+	var lastErr error
+	for {
+		ep, err := p.route(sql, tried)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		tried[ep] = true
+
+		ep.inFlight.Add(1)
+		resp, err := ep.client.ExecuteQuery(ctx, sql, opts...)
+		ep.inFlight.Add(-1)
+		ep.breaker.record(ctx, err)
+		if err == nil || status.Code(err) != codes.Unavailable || !isIdempotent(sql) || ctx.Err() != nil {
+			return resp, err
+		}
+		lastErr = err
+	}
+}
+
+// ExecuteQueryStream 在下一个可用端点上执行流式查询。
+func (p *Pool) ExecuteQueryStream(ctx context.Context, sql string, opts ...QueryOption) (*ResultStream, error) {
+	ep, err := p.route(sql, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ep.inFlight.Add(1)
+	stream, err := ep.client.ExecuteQueryStream(ctx, sql, opts...)
+	if err != nil {
+		ep.inFlight.Add(-1)
+		ep.breaker.record(ctx, err)
+		return nil, err
+	}
+	stream.onEnd = append(stream.onEnd, func(err error) {
+		ep.inFlight.Add(-1)
+		ep.breaker.record(ctx, err)
+	})
+	return stream, nil
+}
+
+// route 根据语句类别选择端点，每次选择只推进一次轮询位置
+func (p *Pool) route(sql string, skip map[*endpoint]bool) (*endpoint, error) {
+	start := p.next.Add(1) - 1
+	if p.primary == nil {
+		return p.pick(p.readers, skip, start)
+	}
+	if classifyStatement(sql) != StatementRead {
+		return p.pick([]*endpoint{p.primary}, skip, start)
+	}
+	if ep, err := p.pick(p.readers, skip, start); err == nil {
+		return ep, nil
+	}
+	return p.pick([]*endpoint{p.primary}, skip, start)
+}
+
+// pick 从 candidates 的第 start 个开始，按轮询顺序选出一个未尝试过、未处于故障状态、健康且未被熔断的端点。
+// 返回的端点已被熔断器放行，调用结束后需要 record 结果
+func (p *Pool) pick(candidates []*endpoint, skip map[*endpoint]bool, start uint64) (*endpoint, error) {
+	n := uint64(len(candidates))
+	if n == 0 {
+		return nil, ErrNoHealthyEndpoint
+	}
+	circuitOpen := false
+	for i := uint64(0); i < n; i++ {
+		ep := candidates[(start+i)%n]
+		if skip[ep] {
+			continue
+		}
+		switch ep.client.conn.GetState() {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			continue
+		case connectivity.Idle:
+			// 空闲连接按需重连
+			ep.client.conn.Connect()
+		}
+		if HealthStatus(ep.health.Load()) == HealthNotServing {
+			continue
+		}
+		if ep.breaker.allow() != nil {
+			circuitOpen = true
+			continue
+		}
+		return ep, nil
+	}
+	if circuitOpen {
+		return nil, ErrCircuitOpen
+	}
+	return nil, ErrNoHealthyEndpoint
+}
+
+// Stats 返回各端点的连接状态和在途查询数。
+func (p *Pool) Stats() []EndpointStats {
+	stats := make([]EndpointStats, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		state := ep.client.conn.GetState()
+		breaker := ep.breaker.current()
+		health := HealthStatus(ep.health.Load())
+		healthy := state != connectivity.TransientFailure && state != connectivity.Shutdown &&
+			breaker != BreakerOpen && health != HealthNotServing
+		stats[i] = EndpointStats{
+			Target:   ep.target,
+			Role:     ep.role,
+			State:    state,
+			Breaker:  breaker,
+			Health:   health,
+			Healthy:  healthy,
+			InFlight: ep.inFlight.Load(),
+		}
+	}
+	return stats
+}
+
+// Close 关闭所有端点的连接，并等待健康检查协程退出。
+func (p *Pool) Close() error {
+	p.stopWatch()
+	defer p.watchers.Wait()
+	var errs []error
+	for _, ep := range p.endpoints {
+		if err := ep.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 %s 失败: %w", ep.target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,122 @@
+package datafusion
+
+import (
+	"context"
+	"testing"
+)
+
+// newNamedPool 启动名为 names 的服务端并创建按顺序连接到它们的连接池
+func newNamedPool(t *testing.T, names ...string) *Pool {
+	t.Helper()
+	targets := make([]string, len(names))
+	for i, name := range names {
+		targets[i] = startServer(t, (&namedServer{name: name}).fake())
+	}
+	p, err := NewPool(targets, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// poolAnswers 执行 n 次只读查询，返回每次应答的服务端名字
+func poolAnswers(t *testing.T, p *Pool, n int) []string {
+	t.Helper()
+	out := make([]string, n)
+	for i := range out {
+		resp, err := p.ExecuteQuery(context.Background(), "SELECT 1")
+		if err != nil {
+			t.Fatalf("第 %d 次查询: %v", i+1, err)
+		}
+		out[i] = resp.Result
+	}
+	return out
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	p := newNamedPool(t, "a", "b", "c")
+
+	got := poolAnswers(t, p, 6)
+	want := []string{"a", "b", "c", "a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("应答顺序 = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPoolFailoverSkipsDeadEndpoint(t *testing.T) {
+	var targets []string
+	stop := map[string]func(){}
+	for _, name := range []string{"a", "b", "c"} {
+		addr, s := serve(t, "127.0.0.1:0", (&namedServer{name: name}).fake())
+		targets = append(targets, addr)
+		stop[name] = s.Stop
+	}
+	p, err := NewPool(targets, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer p.Close()
+	// 先让所有连接建立
+	poolAnswers(t, p, 3)
+
+	stop["b"]()
+	waitDisconnected(t, p.endpoints[1].client)
+
+	counts := map[string]int{}
+	for _, name := range poolAnswers(t, p, 6) {
+		counts[name]++
+	}
+	if counts["b"] != 0 || counts["a"] == 0 || counts["c"] == 0 {
+		t.Errorf("应答分布 = %v, want 只落在 a 和 c 上", counts)
+	}
+	if p.Stats()[1].Healthy {
+		t.Errorf("停止的端点仍报告为健康: %+v", p.Stats()[1])
+	}
+}
+
+func TestPoolAllEndpointsDown(t *testing.T) {
+	addr, s := serve(t, "127.0.0.1:0", (&namedServer{name: "a"}).fake())
+	p, err := NewPool([]string{addr}, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	defer p.Close()
+	poolAnswers(t, p, 1)
+	s.Stop()
+	waitDisconnected(t, p.endpoints[0].client)
+
+	if _, err := p.ExecuteQuery(context.Background(), "SELECT 1"); err == nil {
+		t.Fatal("所有端点都不可用时应返回错误")
+	}
+}
+
+func TestPoolRouteAdvancesOnce(t *testing.T) {
+	primary := startServer(t, (&namedServer{name: RolePrimary}).fake())
+	replica := startServer(t, (&namedServer{name: RoleReplica}).fake())
+	p, err := NewReadWritePool(primary, []string{replica}, WithInsecure())
+	if err != nil {
+		t.Fatalf("NewReadWritePool: %v", err)
+	}
+	defer p.Close()
+	// 唯一的副本报告 NOT_SERVING，读语句在选择副本失败后退回主节点
+	p.readers[0].health.Store(int32(HealthNotServing))
+
+	const n = 4
+	for _, name := range poolAnswers(t, p, n) {
+		if name != RolePrimary {
+			t.Fatalf("副本不可用时读语句发往 %s, want primary", name)
+		}
+	}
+	if got := p.next.Load(); got != n {
+		t.Errorf("%d 次选择推进了轮询位置 %d 次, want 每次一次", n, got)
+	}
+}
+
+func TestNewPoolRequiresTargets(t *testing.T) {
+	if _, err := NewPool(nil); err == nil {
+		t.Fatal("没有端点时 NewPool 应返回错误")
+	}
+}
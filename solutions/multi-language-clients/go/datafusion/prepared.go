@@ -0,0 +1,168 @@
+package datafusion
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// PreparedStatement 是服务端预编译的语句，可并发使用。
+type PreparedStatement struct {
+	client *DataFusionClient
+	sql    string
+
+	mu     sync.Mutex
+	handle string
+}
+
+// Prepare 预编译一条使用 $1、$2 占位符的语句。
+func (c *DataFusionClient) Prepare(ctx context.Context, sql string) (*PreparedStatement, error) {
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	stmt := &PreparedStatement{client: c, sql: sql}
+	if _, err := stmt.prepare(ctx); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// prepare 在服务端重新预编译语句并记录新句柄
+func (s *PreparedStatement) prepare(ctx context.Context) (string, error) {
+	resp, err := s.client.rpc.Prepare(ctx, &pb.PrepareRequest{Sql: s.sql})
+	if err != nil {
+		return "", newQueryError(ctx, s.sql, err)
+	}
+	s.mu.Lock()
+	s.handle = resp.GetHandle()
+	s.mu.Unlock()
+	return resp.GetHandle(), nil
+}
+
+// Query 绑定 args 执行语句。
+// 服务端淘汰了句柄时会透明地重新预编译一次。
+func (s *PreparedStatement) Query(ctx context.Context, args ...any) (*QueryResponse, error) {
+	if err := s.client.validateArgs(s.sql, args); err != nil {
+		return nil, err
+	}
+	params, err := toPBValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := s.client.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	ctx, cancel := s.client.queryContext(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	handle := s.handle
+	s.mu.Unlock()
+
+	var out *QueryResponse
+	err = s.client.instrument(ctx, s.sql, func(ctx context.Context) (int, error) {
+		resp, err := s.exec(ctx, handle, params)
+		if status.Code(err) == codes.NotFound {
+			if handle, err = s.prepare(ctx); err != nil {
+				return 0, err
+			}
+			resp, err = s.exec(ctx, handle, params)
+		}
+		if err != nil {
+			return 0, newQueryError(ctx, s.sql, err)
+		}
+		out = newQueryResponse(ctx, resp, "")
+		return len(resp.GetRows()), nil
+	})
+	return out, err
+}
+
+func (s *PreparedStatement) exec(ctx context.Context, handle string, params []*pb.Value) (*pb.QueryResponse, error) {
+	var resp *pb.QueryResponse
+	err := s.client.withRetry(ctx, isIdempotent(s.sql), func() error {
+		var err error
+		resp, err = s.client.rpc.ExecPrepared(ctx, &pb.ExecPreparedRequest{
+			Handle:     handle,
+			Parameters: params,
+		})
+		return err
+	})
+	return resp, err
+}
+
+func toPBValues(args []any) ([]*pb.Value, error) {
+	out := make([]*pb.Value, len(args))
+	for i, arg := range args {
+		v, err := toPBValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("参数 $%d: %w", i+1, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// toPBValue 将 Go 值转换为 protobuf 值，支持 driver.Valuer (如 sql.NullString)
+func toPBValue(arg any) (*pb.Value, error) {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		arg = v
+	}
+
+	switch v := arg.(type) {
+	case nil:
+		return &pb.Value{}, nil
+	case bool:
+		return &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: v}}, nil
+	case int:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int8:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int16:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int32:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int64:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: v}}, nil
+	case uint8:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case uint16:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case uint32:
+		return &pb.Value{Kind: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case float32:
+		return &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: float64(v)}}, nil
+	case float64:
+		return &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: v}}, nil
+	case string:
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: v}}, nil
+	case []byte:
+		return &pb.Value{Kind: &pb.Value_BytesValue{BytesValue: v}}, nil
+	case time.Time:
+		return &pb.Value{Kind: &pb.Value_TimestampMicros{TimestampMicros: v.UnixMicro()}}, nil
+	default:
+		return nil, fmt.Errorf("不支持的参数类型 %T", arg)
+	}
+}
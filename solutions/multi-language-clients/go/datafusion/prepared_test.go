@@ -0,0 +1,111 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// preparedServer 记录预编译和执行请求，evict 为 true 时淘汰当前句柄一次
+type preparedServer struct {
+	mu       sync.Mutex
+	prepares []string
+	execs    []*pb.ExecPreparedRequest
+	evict    bool
+}
+
+func (p *preparedServer) fake() *fakeServer {
+	return &fakeServer{
+		prepare: func(_ context.Context, req *pb.PrepareRequest) (*pb.PrepareResponse, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.prepares = append(p.prepares, req.GetSql())
+			return &pb.PrepareResponse{Handle: fmt.Sprintf("h-%d", len(p.prepares))}, nil
+		},
+		execPrepared: func(_ context.Context, req *pb.ExecPreparedRequest) (*pb.QueryResponse, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.execs = append(p.execs, req)
+			if p.evict {
+				p.evict = false
+				return nil, status.Error(codes.NotFound, "句柄已淘汰")
+			}
+			return &pb.QueryResponse{Rows: []*pb.Row{{Values: req.GetParameters()}}}, nil
+		},
+	}
+}
+
+func TestPreparedBindsParameters(t *testing.T) {
+	p := &preparedServer{}
+	c := newTestClient(t, p.fake())
+	ctx := context.Background()
+
+	stmt, err := c.Prepare(ctx, "SELECT * FROM users WHERE id = $1 AND name = $2")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	resp, err := stmt.Query(ctx, 42, "alice")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(resp.Rows) != 1 {
+		t.Fatalf("行数 = %d, want 1", len(resp.Rows))
+	}
+
+	if len(p.execs) != 1 {
+		t.Fatalf("ExecPrepared 调用 %d 次, want 1", len(p.execs))
+	}
+	req := p.execs[0]
+	if req.GetHandle() != "h-1" {
+		t.Errorf("句柄 = %q, want h-1", req.GetHandle())
+	}
+	params := req.GetParameters()
+	if len(params) != 2 {
+		t.Fatalf("参数个数 = %d, want 2", len(params))
+	}
+	if got, ok := params[0].GetKind().(*pb.Value_IntValue); !ok || got.IntValue != 42 {
+		t.Errorf("$1 = %v, want int 42", params[0])
+	}
+	if got, ok := params[1].GetKind().(*pb.Value_StringValue); !ok || got.StringValue != "alice" {
+		t.Errorf("$2 = %v, want string alice", params[1])
+	}
+}
+
+func TestPreparedReprepareOnNotFound(t *testing.T) {
+	p := &preparedServer{}
+	c := newTestClient(t, p.fake())
+	ctx := context.Background()
+
+	const sql = "SELECT * FROM users WHERE id = $1"
+	stmt, err := c.Prepare(ctx, sql)
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	p.mu.Lock()
+	p.evict = true
+	p.mu.Unlock()
+
+	if _, err := stmt.Query(ctx, 7); err != nil {
+		t.Fatalf("句柄淘汰后 Query 应重新预编译并成功: %v", err)
+	}
+	if len(p.prepares) != 2 || p.prepares[1] != sql {
+		t.Errorf("Prepare 请求 = %q, want 两次 %q", p.prepares, sql)
+	}
+	if len(p.execs) != 2 || p.execs[0].GetHandle() != "h-1" || p.execs[1].GetHandle() != "h-2" {
+		t.Fatalf("ExecPrepared 句柄不符合预期: %v", p.execs)
+	}
+
+	// 新句柄会被后续调用复用
+	if _, err := stmt.Query(ctx, 8); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := p.execs[2].GetHandle(); got != "h-2" || len(p.prepares) != 2 {
+		t.Errorf("第三次执行句柄 = %q, Prepare %d 次, want h-2 且不再预编译", got, len(p.prepares))
+	}
+}
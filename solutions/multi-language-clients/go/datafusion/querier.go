@@ -0,0 +1,16 @@
+package datafusion
+
+import "context"
+
+// Querier 是执行查询的最小接口。
+// *DataFusionClient 和 *Pool 都实现了该接口，测试中可以用
+// clienttest.FakeQuerier 替换。
+type Querier interface {
+	ExecuteQuery(ctx context.Context, sql string, opts ...QueryOption) (*QueryResponse, error)
+	ExecuteQueryStream(ctx context.Context, sql string, opts ...QueryOption) (*ResultStream, error)
+}
+
+var (
+	_ Querier = (*DataFusionClient)(nil)
+	_ Querier = (*Pool)(nil)
+)
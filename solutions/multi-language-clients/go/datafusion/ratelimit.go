@@ -0,0 +1,87 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited 表示调用超出了 WithRateLimit 配置的速率。
+var ErrRateLimited = errors.New("超出客户端限流")
+
+// WithRateLimit 将客户端发起的请求限制在每秒 qps 个，允许 burst 个突发。
+// 超出速率的调用会等待令牌，等待受调用方上下文控制；
+// 配合 WithRateLimitFailFast 可改为立即返回 ErrRateLimited。
+func WithRateLimit(qps float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = rate.Limit(qps)
+		o.rateBurst = burst
+	}
+}
+
+// WithRateLimitFailFast 让超出速率的调用立即返回 ErrRateLimited 而不是等待。
+func WithRateLimitFailFast() Option {
+	return func(o *options) {
+		o.rateFailFast = true
+	}
+}
+
+// rateLimiter 在发起请求前获取令牌，未配置限流时为 nil
+type rateLimiter struct {
+	limiter  *rate.Limiter
+	failFast bool
+	metrics  *Metrics
+}
+
+func newRateLimiter(o *options, m *Metrics) *rateLimiter {
+	if o.rateLimit <= 0 {
+		return nil
+	}
+	burst := o.rateBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limiter:  rate.NewLimiter(o.rateLimit, burst),
+		failFast: o.rateFailFast,
+		metrics:  m,
+	}
+}
+
+// wait 获取一个令牌，需要等待或被拒绝时计入限流指标
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil || l.limiter.Allow() {
+		return nil
+	}
+	l.metrics.observeThrottled()
+	if l.failFast {
+		return ErrRateLimited
+	}
+	if err := l.limiter.Wait(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// 截止时间之前拿不到令牌
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	}
+	return nil
+}
+
+// admit 登记一次调用、按限流获取令牌并等待断开的连接恢复，返回的 done 在调用结束时执行
+func (c *DataFusionClient) admit(ctx context.Context) (done func(), err error) {
+	done, err = c.calls.begin()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.limiter.wait(ctx); err != nil {
+		done()
+		return nil, err
+	}
+	if err := c.awaitReconnect(ctx); err != nil {
+		done()
+		return nil, err
+	}
+	return done, nil
+}
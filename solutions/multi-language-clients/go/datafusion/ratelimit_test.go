@@ -0,0 +1,84 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWithRateLimitDelaysExcessCalls(t *testing.T) {
+	const qps, n = 20, 5
+	srv := &countingServer{}
+	reg := prometheus.NewRegistry()
+	c := newTestClient(t, srv.fake(), WithRateLimit(qps, 1), WithMetrics(reg))
+
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// 突发 1 个之后每 50ms 一个令牌，留出计时抖动的余量
+	floor := time.Duration(n-1) * time.Second / qps * 3 / 4
+	if elapsed < floor {
+		t.Errorf("%d 次调用用时 %v, want 至少 %v", n, elapsed, floor)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("%d 次调用用时 %v，等待时间过长", n, elapsed)
+	}
+	if got := srv.calls.Load(); got != n {
+		t.Errorf("服务端收到 %d 次调用, want %d", got, n)
+	}
+	if got := testutil.ToFloat64(c.metrics.throttled); got < 1 {
+		t.Errorf("throttled_requests_total = %v, want 至少 1", got)
+	}
+}
+
+func TestWithRateLimitBurstNotDelayed(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithRateLimit(1, 3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+			t.Fatalf("突发范围内的第 %d 次调用失败: %v", i+1, err)
+		}
+	}
+}
+
+func TestWithRateLimitFailFast(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithRateLimit(0.1, 1), WithRateLimitFailFast())
+
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if _, err := c.ExecuteQuery(ctx, "SELECT 2"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("ExecuteQuery = %v, want ErrRateLimited", err)
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Errorf("被限流的调用不应到达服务端，服务端收到 %d 次", got)
+	}
+}
+
+func TestWithRateLimitDeadlineTooShort(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithRateLimit(0.1, 1))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 2"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("截止时间前拿不到令牌时应返回 ErrRateLimited, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package datafusion
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// WithReconnect 在连接断开后立即重连，不等下一次调用触发；
+// 连续失败时按指数退避重试，两次尝试的间隔不超过 maxBackoff。
+// 每次连接尝试的时限由 WithDialTimeout 决定，未设置时为 gRPC 默认的 20 秒。
+// 断开后的每次重连都会记录日志并累加 datafusion_reconnects_total，
+// 连续失败期间 gRPC 保持 TRANSIENT_FAILURE 状态，后续的退避重试不再单独计数。
+func WithReconnect(maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.reconnect = true
+		o.reconnectBackoff = maxBackoff
+	}
+}
+
+// WithReconnectGrace 让连接不可用期间发起的调用最多等待 d 让连接恢复，
+// 超过 d 仍未恢复时照常发送并返回 Unavailable。默认不等待。
+func WithReconnectGrace(d time.Duration) Option {
+	return func(o *options) {
+		o.reconnectGrace = d
+	}
+}
+
+// awaitReconnect 在连接未就绪时等待其恢复，最多等待 reconnectGrace。
+// 只有 ctx 结束时返回错误，等待超时交由 RPC 自身报告连接错误
+func (c *DataFusionClient) awaitReconnect(ctx context.Context) error {
+	if c.opts.reconnectGrace <= 0 || c.conn.GetState() == connectivity.Ready {
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, c.opts.reconnectGrace)
+	defer cancel()
+	if err := c.WaitForReady(waitCtx); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
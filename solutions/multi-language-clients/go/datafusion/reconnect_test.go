@@ -0,0 +1,107 @@
+package datafusion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestReconnectAfterServerRestart(t *testing.T) {
+	addr, s := serve(t, "127.0.0.1:0", (&countingServer{}).fake())
+	c := dialTestClient(t, addr,
+		WithReconnect(50*time.Millisecond),
+		WithReconnectGrace(5*time.Second),
+		WithMetrics(prometheus.NewRegistry()),
+	)
+	ctx := context.Background()
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	s.Stop()
+	waitDisconnected(t, c)
+	restarted := &countingServer{}
+	serve(t, addr, restarted.fake())
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("服务端重启后 ExecuteQuery: %v", err)
+	}
+	if got := restarted.calls.Load(); got != 1 {
+		t.Errorf("重启后的服务端收到 %d 次查询, want 1", got)
+	}
+	if c.State() != connectivity.Ready {
+		t.Errorf("State = %v, want READY", c.State())
+	}
+	if got := testutil.ToFloat64(c.metrics.reconnects); got < 1 {
+		t.Errorf("reconnects = %v, want >= 1", got)
+	}
+}
+
+func TestReconnectGraceGivesUpWhenServerStaysDown(t *testing.T) {
+	addr, s := serve(t, "127.0.0.1:0", (&countingServer{}).fake())
+	c := dialTestClient(t, addr,
+		WithReconnect(20*time.Millisecond),
+		WithReconnectGrace(100*time.Millisecond),
+	)
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	s.Stop()
+	waitDisconnected(t, c)
+	start := time.Now()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err == nil {
+		t.Fatal("服务端未恢复时查询应失败")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("等待了 %s，超出重连宽限", elapsed)
+	}
+}
+
+func TestConnectParams(t *testing.T) {
+	build := func(opts ...Option) options {
+		o := defaultOptions()
+		for _, opt := range opts {
+			opt(&o)
+		}
+		return o
+	}
+
+	o := build()
+	if _, ok := o.connectParams(); ok {
+		t.Error("未配置时应沿用 gRPC 默认的连接参数")
+	}
+
+	o = build(WithReconnect(time.Second))
+	params, ok := o.connectParams()
+	if !ok {
+		t.Fatal("配置了重连退避时应设置连接参数")
+	}
+	if params.MinConnectTimeout != defaultMinConnectTimeout {
+		t.Errorf("MinConnectTimeout = %s, want %s (默认的建连超时不应影响单次连接尝试)",
+			params.MinConnectTimeout, defaultMinConnectTimeout)
+	}
+	if params.Backoff.MaxDelay != time.Second || params.Backoff.BaseDelay != time.Second {
+		t.Errorf("Backoff = %+v", params.Backoff)
+	}
+
+	o = build(WithDialTimeout(3*time.Second), WithReconnect(time.Minute))
+	params, ok = o.connectParams()
+	if !ok || params.MinConnectTimeout != 3*time.Second {
+		t.Errorf("MinConnectTimeout = %s, want 3s", params.MinConnectTimeout)
+	}
+	if params.Backoff.MaxDelay != time.Minute {
+		t.Errorf("MaxDelay = %s, want 1m", params.Backoff.MaxDelay)
+	}
+
+	o = build(WithDialTimeout(3 * time.Second))
+	if params, ok := o.connectParams(); !ok || params.MinConnectTimeout != 3*time.Second {
+		t.Errorf("只设置建连超时时 MinConnectTimeout = %s, %v", params.MinConnectTimeout, ok)
+	}
+}
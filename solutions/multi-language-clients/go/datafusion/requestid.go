@@ -0,0 +1,54 @@
+package datafusion
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader 是携带请求 ID 的元数据键，服务端会将其写入日志
+const requestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// WithRequestID 返回携带请求 ID 的上下文，使用该上下文的调用都以 id 发送。
+// 未指定时客户端为每次调用生成一个 UUID。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext 返回上下文中的请求 ID。
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ensureRequestID 在上下文没有请求 ID 时生成一个，
+// 使重试与故障转移沿用同一 ID
+func ensureRequestID(ctx context.Context) context.Context {
+	if _, ok := RequestIDFromContext(ctx); ok {
+		return ctx
+	}
+	return WithRequestID(ctx, uuid.NewString())
+}
+
+// requestID 返回上下文中的请求 ID，没有时返回空串
+func requestID(ctx context.Context) string {
+	id, _ := RequestIDFromContext(ctx)
+	return id
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	ctx = ensureRequestID(ctx)
+	return metadata.AppendToOutgoingContext(ctx, requestIDHeader, requestID(ctx))
+}
+
+func requestIDUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+func requestIDStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+}
@@ -0,0 +1,109 @@
+package datafusion
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// requestIDServer 记录每次调用收到的 x-request-id，前 failures 次调用返回 Unavailable
+func requestIDServer(failures int, got *[]string) *fakeServer {
+	var mu sync.Mutex
+	return &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			*got = append(*got, md.Get(requestIDHeader)...)
+			if len(*got) <= failures {
+				return nil, status.Error(codes.Unavailable, "暂时不可用")
+			}
+			return &pb.QueryResponse{}, nil
+		},
+	}
+}
+
+func TestRequestIDKeepsCallerID(t *testing.T) {
+	var got []string
+	c := newTestClient(t, requestIDServer(0, &got))
+
+	ctx := WithRequestID(context.Background(), "caller-42")
+	resp, err := c.ExecuteQuery(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(got) != 1 || got[0] != "caller-42" {
+		t.Errorf("服务端收到的 x-request-id = %q, want [caller-42]", got)
+	}
+	if resp.RequestID != "caller-42" {
+		t.Errorf("RequestID = %q, want caller-42", resp.RequestID)
+	}
+}
+
+func TestRequestIDGeneratedWhenAbsent(t *testing.T) {
+	var got []string
+	c := newTestClient(t, requestIDServer(0, &got))
+
+	ctx := context.Background()
+	first, err := c.ExecuteQuery(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	second, err := c.ExecuteQuery(ctx, "SELECT 2")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("服务端收到的 x-request-id = %q, want 每次调用一个", got)
+	}
+	for i, id := range got {
+		if _, err := uuid.Parse(id); err != nil {
+			t.Errorf("第 %d 次调用的请求 ID %q 不是 UUID", i+1, id)
+		}
+	}
+	if got[0] == got[1] {
+		t.Errorf("不同调用应生成不同的请求 ID: %q", got)
+	}
+	if first.RequestID != got[0] || second.RequestID != got[1] {
+		t.Errorf("RequestID = %q, %q, want 与服务端收到的 %q 一致", first.RequestID, second.RequestID, got)
+	}
+}
+
+func TestRequestIDSharedAcrossRetries(t *testing.T) {
+	var got []string
+	c := newTestClient(t, requestIDServer(2, &got), WithRetry(3, time.Millisecond))
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("服务端收到 %d 次调用, want 3", len(got))
+	}
+	for i, id := range got {
+		if id != resp.RequestID {
+			t.Errorf("第 %d 次尝试的请求 ID = %q, want %q", i+1, id, resp.RequestID)
+		}
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if _, ok := RequestIDFromContext(context.Background()); ok {
+		t.Error("没有请求 ID 的上下文应返回 false")
+	}
+	if _, ok := RequestIDFromContext(WithRequestID(context.Background(), "")); ok {
+		t.Error("空请求 ID 应视为未设置")
+	}
+	if id, ok := RequestIDFromContext(WithRequestID(context.Background(), "abc")); !ok || id != "abc" {
+		t.Errorf("RequestIDFromContext = %q, %v, want abc, true", id, ok)
+	}
+}
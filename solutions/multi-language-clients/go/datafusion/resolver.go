@@ -0,0 +1,245 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"datafusion-client/pb"
+)
+
+// ClusterScheme 是集群目标的 URI scheme，目标形如 datafusion:///cluster-name。
+const ClusterScheme = "datafusion"
+
+// staticScheme 是固定地址列表使用的 scheme
+const staticScheme = "datafusion-static"
+
+const (
+	// 默认的成员刷新间隔
+	defaultMembershipRefresh = 30 * time.Second
+	// 单次查询成员列表的超时
+	listMembersTimeout = 5 * time.Second
+	// 使用 gRPC 内置的 round_robin 负载均衡
+	roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+)
+
+type clusterConfig struct {
+	seeds   []string
+	refresh time.Duration
+}
+
+// WithClusterResolver 让客户端可以连接 datafusion:///cluster-name 形式的目标。
+// 客户端向 seeds 中的种子节点查询集群成员，每隔 refresh 刷新一次，
+// 并以 round_robin 方式在成员间分发请求，集群扩缩容时无需重新拨号。
+// refresh 为 0 时使用默认的 30 秒。
+func WithClusterResolver(seeds []string, refresh time.Duration) Option {
+	return func(o *options) {
+		if refresh <= 0 {
+			refresh = defaultMembershipRefresh
+		}
+		o.cluster = &clusterConfig{seeds: seeds, refresh: refresh}
+	}
+}
+
+// withStaticAddresses 让 staticScheme 目标在 addrs 间以 round_robin 分发请求
+func withStaticAddresses(addrs []string) Option {
+	return func(o *options) {
+		o.staticAddrs = addrs
+	}
+}
+
+func newStaticBuilder(addrs []string) resolver.Builder {
+	r := manual.NewBuilderWithScheme(staticScheme)
+	state := resolver.State{Addresses: make([]resolver.Address, len(addrs))}
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: addr}
+	}
+	r.InitialState(state)
+	return r
+}
+
+// memberLister 返回集群成员地址
+type memberLister interface {
+	ListMembers(ctx context.Context, cluster string) ([]string, error)
+	Close() error
+}
+
+// clusterBuilder 为 datafusion scheme 创建解析器
+type clusterBuilder struct {
+	refresh   time.Duration
+	newLister func() memberLister
+}
+
+func newClusterBuilder(cfg *clusterConfig, dialOpts ...grpc.DialOption) *clusterBuilder {
+	return &clusterBuilder{
+		refresh: cfg.refresh,
+		newLister: func() memberLister {
+			return &seedLister{seeds: cfg.seeds, dialOpts: dialOpts}
+		},
+	}
+}
+
+func (b *clusterBuilder) Scheme() string {
+	return ClusterScheme
+}
+
+func (b *clusterBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	cluster := target.Endpoint()
+	if cluster == "" {
+		return nil, fmt.Errorf("无效的集群目标 %q: 缺少集群名", target.URL.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &clusterResolver{
+		cluster:    cluster,
+		lister:     b.newLister(),
+		cc:         cc,
+		refresh:    b.refresh,
+		ctx:        ctx,
+		cancel:     cancel,
+		resolveNow: make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+// clusterResolver 定期刷新成员列表并推送给 gRPC
+type clusterResolver struct {
+	cluster    string
+	lister     memberLister
+	cc         resolver.ClientConn
+	refresh    time.Duration
+	ctx        context.Context
+	cancel     context.CancelFunc
+	resolveNow chan struct{}
+	wg         sync.WaitGroup
+
+	// last 是最近一次推送的地址，仅由 watch 协程访问
+	last []string
+}
+
+func (r *clusterResolver) watch() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+
+	for {
+		r.update()
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+// update 查询成员列表，有变化时更新连接的地址
+func (r *clusterResolver) update() {
+	ctx, cancel := context.WithTimeout(r.ctx, listMembersTimeout)
+	defer cancel()
+
+	addrs, err := r.lister.ListMembers(ctx, r.cluster)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("集群 %s 没有可用成员", r.cluster)
+	}
+	if err != nil {
+		if r.ctx.Err() == nil {
+			r.cc.ReportError(err)
+		}
+		return
+	}
+
+	addrs = slices.Clone(addrs)
+	slices.Sort(addrs)
+	addrs = slices.Compact(addrs)
+	if slices.Equal(addrs, r.last) {
+		return
+	}
+
+	state := resolver.State{Addresses: make([]resolver.Address, len(addrs))}
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: addr}
+	}
+	if err := r.cc.UpdateState(state); err == nil {
+		r.last = addrs
+	}
+}
+
+func (r *clusterResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *clusterResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+	_ = r.lister.Close()
+}
+
+// seedLister 依次向种子节点查询成员列表，种子节点的连接按需建立并复用
+type seedLister struct {
+	seeds    []string
+	dialOpts []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func (l *seedLister) ListMembers(ctx context.Context, cluster string) ([]string, error) {
+	if len(l.seeds) == 0 {
+		return nil, errors.New("没有配置种子节点")
+	}
+
+	var errs []error
+	for _, seed := range l.seeds {
+		conn, err := l.conn(seed)
+		if err == nil {
+			var resp *pb.ListMembersResponse
+			resp, err = pb.NewDataFusionClient(conn).ListMembers(ctx, &pb.ListMembersRequest{Cluster: cluster})
+			if err == nil {
+				return resp.GetAddresses(), nil
+			}
+		}
+		errs = append(errs, fmt.Errorf("种子节点 %s: %w", seed, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (l *seedLister) conn(seed string) (*grpc.ClientConn, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if conn, ok := l.conns[seed]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(seed, l.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if l.conns == nil {
+		l.conns = make(map[string]*grpc.ClientConn)
+	}
+	l.conns[seed] = conn
+	return conn, nil
+}
+
+func (l *seedLister) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var errs []error
+	for _, conn := range l.conns {
+		errs = append(errs, conn.Close())
+	}
+	l.conns = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,124 @@
+package datafusion
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"datafusion-client/pb"
+)
+
+// namedServer 在结果中返回自己的名字，用于判断请求落在哪个成员上
+type namedServer struct {
+	name string
+}
+
+func (s *namedServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *namedServer) executeQuery(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+	return &pb.QueryResponse{Result: s.name}, nil
+}
+
+// stubLister 返回可在测试中替换的成员列表
+type stubLister struct {
+	mu      sync.Mutex
+	members []string
+	cluster string
+}
+
+func (l *stubLister) set(members []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.members = members
+}
+
+func (l *stubLister) ListMembers(ctx context.Context, cluster string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cluster = cluster
+	return slices.Clone(l.members), nil
+}
+
+func (l *stubLister) Close() error { return nil }
+
+// servedBy 反复查询直到见到 want 中的所有成员，返回见到的成员
+func servedBy(t *testing.T, rpc pb.DataFusionClient, want int) map[string]bool {
+	t.Helper()
+	seen := map[string]bool{}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(seen) < want && time.Now().Before(deadline) {
+		resp, err := rpc.ExecuteQuery(context.Background(), &pb.QueryRequest{Sql: "SELECT 1"})
+		if err != nil {
+			t.Fatalf("ExecuteQuery: %v", err)
+		}
+		seen[resp.GetResult()] = true
+	}
+	return seen
+}
+
+func TestClusterResolverPicksUpNewMembers(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	addrs := make(map[string]string)
+	for _, name := range names {
+		addrs[name] = startServer(t, (&namedServer{name: name}).fake())
+	}
+
+	lister := &stubLister{}
+	lister.set([]string{addrs["a"], addrs["b"]})
+	b := &clusterBuilder{
+		refresh:   20 * time.Millisecond,
+		newLister: func() memberLister { return lister },
+	}
+
+	conn, err := grpc.Dial(ClusterScheme+":///analytics",
+		grpc.WithResolvers(b),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	rpc := pb.NewDataFusionClient(conn)
+
+	seen := servedBy(t, rpc, 2)
+	if !seen["a"] || !seen["b"] || len(seen) != 2 {
+		t.Fatalf("两个成员时请求落在 %v", seen)
+	}
+
+	lister.set([]string{addrs["a"], addrs["b"], addrs["c"]})
+	if seen := servedBy(t, rpc, 3); !seen["c"] {
+		t.Fatalf("新增成员后请求仍只落在 %v", seen)
+	}
+
+	lister.mu.Lock()
+	cluster := lister.cluster
+	lister.mu.Unlock()
+	if cluster != "analytics" {
+		t.Errorf("查询的集群 = %q, want analytics", cluster)
+	}
+}
+
+func TestClusterResolverRequiresClusterName(t *testing.T) {
+	b := &clusterBuilder{
+		refresh:   time.Second,
+		newLister: func() memberLister { return &stubLister{} },
+	}
+	conn, err := grpc.Dial(ClusterScheme+":///",
+		grpc.WithResolvers(b),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err == nil {
+		conn.Close()
+		t.Fatal("缺少集群名时应返回错误")
+	}
+}
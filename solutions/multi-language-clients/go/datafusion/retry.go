@@ -0,0 +1,93 @@
+package datafusion
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 单次重试等待的上限
+const maxRetryDelay = 5 * time.Second
+
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// WithRetry 对只读查询在遇到临时故障 (Unavailable、DeadlineExceeded) 时
+// 按指数退避加随机抖动重试，最多尝试 maxAttempts 次。
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(o *options) {
+		o.retry = retryPolicy{maxAttempts: maxAttempts, baseDelay: baseDelay}
+	}
+}
+
+// isRetryable 判断错误是否为可重试的临时故障
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent 判断语句是否可以安全地重复执行，只有只读语句可以
+func isIdempotent(sql string) bool {
+	return classifyStatement(sql) == StatementRead
+}
+
+// backoffDelay 计算第 attempt 次失败后的等待时间，在 [d/2, d) 区间内抖动
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// withRetry 按重试策略执行 call，只有 idempotent 为 true 时才会重试。
+// 等待不会超出 ctx 的截止时间。
+func (c *DataFusionClient) withRetry(ctx context.Context, idempotent bool, call func() error) error {
+	attempts := 1
+	if c.opts.retry.maxAttempts > 1 && idempotent {
+		attempts = c.opts.retry.maxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= attempts || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoffDelay(c.opts.retry.baseDelay, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		c.opts.logger.Warn("请求失败，稍后重试",
+			"request_id", requestID(ctx),
+			"attempt", attempt,
+			"delay", delay,
+			"code", status.Code(err).String(),
+		)
+		c.opts.hooks.retry(attempt, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
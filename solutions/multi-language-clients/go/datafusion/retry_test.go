@@ -0,0 +1,86 @@
+package datafusion
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// flakyServer 前 failures 次调用返回 code，之后成功
+type flakyServer struct {
+	failures int32
+	code     codes.Code
+	calls    atomic.Int32
+}
+
+func (s *flakyServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *flakyServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if s.calls.Add(1) <= s.failures {
+		return nil, status.Error(s.code, "暂时不可用")
+	}
+	return &pb.QueryResponse{Result: "ok"}, nil
+}
+
+func TestRetryUnavailableThenSucceed(t *testing.T) {
+	srv := &flakyServer{failures: 2, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond))
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if resp.Result != "ok" {
+		t.Errorf("Result = %q, want ok", resp.Result)
+	}
+	if got := srv.calls.Load(); got != 3 {
+		t.Errorf("尝试次数 = %d, want 3", got)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := &flakyServer{failures: 5, code: codes.Unavailable}
+	c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond))
+
+	_, err := c.ExecuteQuery(context.Background(), "SELECT 1")
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+	if got := srv.calls.Load(); got != 3 {
+		t.Errorf("尝试次数 = %d, want 3", got)
+	}
+}
+
+func TestRetrySkipsWritesAndPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		code codes.Code
+	}{
+		{"写语句", "INSERT INTO t VALUES (1)", codes.Unavailable},
+		{"不可重试的错误", "SELECT 1", codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &flakyServer{failures: 1, code: tt.code}
+			c := newTestClient(t, srv.fake(), WithRetry(3, time.Millisecond))
+
+			if _, err := c.ExecuteQuery(context.Background(), tt.sql); status.Code(err) != tt.code {
+				t.Fatalf("err = %v, want %v", err, tt.code)
+			}
+			if got := srv.calls.Load(); got != 1 {
+				t.Errorf("尝试次数 = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package datafusion
+
+import (
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// Column 描述结果集中的一列。
+type Column struct {
+	Name string
+	// DataType 是 Arrow 数据类型名，如 Int64、Utf8
+	DataType string
+	Nullable bool
+}
+
+// Row 是一行数据。NULL 值表示为 nil，其余值为
+// bool、int64、float64、string、[]byte 或 time.Time。
+type Row []any
+
+// RowBatch 是流式查询返回的一批行。
+type RowBatch struct {
+	// Columns 是结果集的列定义
+	Columns []Column
+	Rows    []Row
+}
+
+func columnsFromPB(cols []*pb.Column) []Column {
+	if len(cols) == 0 {
+		return nil
+	}
+	out := make([]Column, len(cols))
+	for i, c := range cols {
+		out[i] = Column{
+			Name:     c.GetName(),
+			DataType: c.GetDataType(),
+			Nullable: c.GetNullable(),
+		}
+	}
+	return out
+}
+
+func rowsFromPB(rows []*pb.Row) []Row {
+	if len(rows) == 0 {
+		return nil
+	}
+	out := make([]Row, len(rows))
+	for i, r := range rows {
+		row := make(Row, len(r.GetValues()))
+		for j, v := range r.GetValues() {
+			row[j] = valueFromPB(v)
+		}
+		out[i] = row
+	}
+	return out
+}
+
+func valueFromPB(v *pb.Value) any {
+	switch k := v.GetKind().(type) {
+	case *pb.Value_BoolValue:
+		return k.BoolValue
+	case *pb.Value_IntValue:
+		return k.IntValue
+	case *pb.Value_DoubleValue:
+		return k.DoubleValue
+	case *pb.Value_StringValue:
+		return k.StringValue
+	case *pb.Value_BytesValue:
+		return k.BytesValue
+	case *pb.Value_TimestampMicros:
+		return time.UnixMicro(k.TimestampMicros).UTC()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package datafusion
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RowScanner 按行遍历查询结果，用法与 database/sql 的 Rows 相同：
+//
+//	rows := resp.Scanner()
+//	for rows.Next() {
+//		var name string
+//		var age int64
+//		if err := rows.Scan(&name, &age); err != nil {
+//			return err
+//		}
+//	}
+type RowScanner struct {
+	columns []Column
+	rows    []Row
+	pos     int
+}
+
+// Scanner 返回遍历结果集的 RowScanner。
+func (r *QueryResponse) Scanner() *RowScanner {
+	return &RowScanner{columns: r.Columns, rows: r.Rows, pos: -1}
+}
+
+// Columns 返回结果集的列定义。
+func (s *RowScanner) Columns() []Column {
+	return s.columns
+}
+
+// Next 前进到下一行，没有更多行时返回 false。
+func (s *RowScanner) Next() bool {
+	if s.pos+1 >= len(s.rows) {
+		s.pos = len(s.rows)
+		return false
+	}
+	s.pos++
+	return true
+}
+
+// Scan 将当前行的各列依次写入 dest。
+// dest 可以是 *string、*int64、*int、*float64、*bool、*[]byte、
+// *time.Time、*any 或实现了 sql.Scanner 的类型 (如 sql.NullString)。
+// NULL 只能写入 *any 或 sql.Scanner。
+func (s *RowScanner) Scan(dest ...any) error {
+	if s.pos < 0 || s.pos >= len(s.rows) {
+		return errors.New("Scan 前需要先调用 Next")
+	}
+	return scanRow(s.columns, s.rows[s.pos], dest)
+}
+
+func scanRow(columns []Column, row Row, dest []any) error {
+	if len(dest) != len(row) {
+		return fmt.Errorf("目标数量 %d 与列数 %d 不一致", len(dest), len(row))
+	}
+	for i, v := range row {
+		if err := scanValue(dest[i], v); err != nil {
+			return fmt.Errorf("扫描第 %d 列 %s 失败: %w", i+1, columnName(columns, i), err)
+		}
+	}
+	return nil
+}
+
+func columnName(columns []Column, i int) string {
+	if i < len(columns) && columns[i].Name != "" {
+		return fmt.Sprintf("%q", columns[i].Name)
+	}
+	return "(未命名)"
+}
+
+func scanValue(dest, v any) error {
+	switch d := dest.(type) {
+	case sql.Scanner:
+		return d.Scan(v)
+	case *any:
+		*d = v
+		return nil
+	}
+	if v == nil {
+		return fmt.Errorf("NULL 无法写入 %T，请使用 sql.Null* 类型", dest)
+	}
+	switch d := dest.(type) {
+	case *string:
+		switch x := v.(type) {
+		case string:
+			*d = x
+			return nil
+		case []byte:
+			*d = string(x)
+			return nil
+		}
+	case *[]byte:
+		switch x := v.(type) {
+		case []byte:
+			*d = append([]byte(nil), x...)
+			return nil
+		case string:
+			*d = []byte(x)
+			return nil
+		}
+	case *int64:
+		if x, ok := v.(int64); ok {
+			*d = x
+			return nil
+		}
+	case *int:
+		if x, ok := v.(int64); ok {
+			*d = int(x)
+			return nil
+		}
+	case *float64:
+		switch x := v.(type) {
+		case float64:
+			*d = x
+			return nil
+		case int64:
+			*d = float64(x)
+			return nil
+		}
+	case *bool:
+		if x, ok := v.(bool); ok {
+			*d = x
+			return nil
+		}
+	case *time.Time:
+		if x, ok := v.(time.Time); ok {
+			*d = x
+			return nil
+		}
+	default:
+		return fmt.Errorf("不支持的目标类型 %T", dest)
+	}
+	return fmt.Errorf("无法将 %T 写入 %T", v, dest)
+}
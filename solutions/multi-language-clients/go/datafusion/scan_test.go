@@ -0,0 +1,112 @@
+package datafusion
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+func TestRowScannerNullIntoNullString(t *testing.T) {
+	srv := &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{Rows: []*pb.Row{
+				{Values: []*pb.Value{mustPBValue(int64(1)), mustPBValue("alice")}},
+				{Values: []*pb.Value{mustPBValue(int64(2)), mustPBValue(nil)}},
+			}}, nil
+		},
+	}
+	c := newTestClient(t, srv)
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT id, nickname FROM users")
+	if err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	rows := resp.Scanner()
+	var got []sql.NullString
+	for rows.Next() {
+		var id int64
+		var nick sql.NullString
+		if err := rows.Scan(&id, &nick); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		got = append(got, nick)
+	}
+	want := []sql.NullString{{String: "alice", Valid: true}, {}}
+	if len(got) != len(want) {
+		t.Fatalf("扫描了 %d 行, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 行 nickname = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestRowScannerNullIntoPlainType(t *testing.T) {
+	rows := scanResponse([]string{"nickname"}, Row{nil}).Scanner()
+	if !rows.Next() {
+		t.Fatal("Next = false, want true")
+	}
+	var nick string
+	if err := rows.Scan(&nick); err == nil {
+		t.Error("NULL 写入 *string 应返回错误")
+	}
+	var v any = "旧值"
+	if err := rows.Scan(&v); err != nil || v != nil {
+		t.Errorf("Scan(*any) = %v, 值 %v, want nil 错误和 nil 值", err, v)
+	}
+}
+
+func TestRowScannerTypes(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := scanResponse([]string{"s", "b", "i", "n", "f", "ok", "ts"},
+		Row{"text", []byte("raw"), int64(7), int64(8), int64(9), true, ts},
+	).Scanner()
+	if !rows.Next() {
+		t.Fatal("Next = false, want true")
+	}
+	var (
+		s  string
+		b  []byte
+		i  int64
+		n  int
+		f  float64
+		ok bool
+		at time.Time
+	)
+	if err := rows.Scan(&s, &b, &i, &n, &f, &ok, &at); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if s != "text" || string(b) != "raw" || i != 7 || n != 8 || f != 9 || !ok || !at.Equal(ts) {
+		t.Errorf("Scan 结果 = %q %q %d %d %v %v %v", s, b, i, n, f, ok, at)
+	}
+	if rows.Next() {
+		t.Error("只有一行时第二次 Next 应返回 false")
+	}
+}
+
+func TestRowScannerErrors(t *testing.T) {
+	rows := scanResponse([]string{"id", "name"}, Row{int64(1), "alice"}).Scanner()
+
+	var id int64
+	var name string
+	if err := rows.Scan(&id, &name); err == nil {
+		t.Error("Next 之前 Scan 应返回错误")
+	}
+	rows.Next()
+	if err := rows.Scan(&id); err == nil {
+		t.Error("目标数量与列数不一致时应返回错误")
+	}
+	if err := rows.Scan(&name, &id); err == nil {
+		t.Error("类型不匹配时应返回错误")
+	}
+	if rows.Next() {
+		t.Fatal("Next = true, want false")
+	}
+	if err := rows.Scan(&id, &name); err == nil {
+		t.Error("遍历结束后 Scan 应返回错误")
+	}
+}
@@ -0,0 +1,119 @@
+package datafusion
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// sessionSettingHeader 是携带会话设置的元数据键，每个值形如 key=value
+const sessionSettingHeader = "x-datafusion-setting"
+
+// 会话设置项名只允许字母、数字、下划线和点，如 datafusion.execution.batch_size
+var settingKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// WithSessionDefaults 设置默认的会话配置，如
+// datafusion.execution.batch_size、datafusion.execution.target_partitions、
+// datafusion.execution.time_zone。
+// 每一项随每次调用以一条 x-datafusion-setting: key=value 元数据发送，
+// 服务端在执行该调用前应用，因此重连或切换到其他节点后依然生效。
+// 值只能包含可打印 ASCII 字符，否则 NewClient 返回 ErrInvalidMetadata。
+func WithSessionDefaults(settings map[string]string) Option {
+	return func(o *options) {
+		o.sessionDefaults = settings
+	}
+}
+
+// sessionSettings 保存客户端的会话配置并附加到每次调用
+type sessionSettings struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func newSessionSettings(defaults map[string]string) (*sessionSettings, error) {
+	s := &sessionSettings{values: make(map[string]string, len(defaults))}
+	for k, v := range defaults {
+		if err := validateSetting(k, v); err != nil {
+			return nil, err
+		}
+		s.values[k] = v
+	}
+	return s, nil
+}
+
+// validateSetting 检查会话配置项名，并按元数据规则检查发送的 key=value
+func validateSetting(key, value string) error {
+	if !settingKeyPattern.MatchString(key) {
+		return fmt.Errorf("无效的会话配置项: %q", key)
+	}
+	if err := validateMetadata(sessionSettingHeader, key+"="+value); err != nil {
+		return fmt.Errorf("会话配置项 %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *sessionSettings) set(key, value string) {
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+}
+
+// pairs 返回按键排序的 key=value 列表
+func (s *sessionSettings) pairs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.values) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(s.values))
+	for k, v := range s.values {
+		out = append(out, k+"="+v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *sessionSettings) outgoing(ctx context.Context) context.Context {
+	pairs := s.pairs()
+	if len(pairs) == 0 {
+		return ctx
+	}
+	kv := make([]string, 0, 2*len(pairs))
+	for _, p := range pairs {
+		kv = append(kv, sessionSettingHeader, p)
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func (s *sessionSettings) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(s.outgoing(ctx), method, req, reply, cc, opts...)
+}
+
+func (s *sessionSettings) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(s.outgoing(ctx), desc, cc, method, opts...)
+}
+
+// SetSessionConfig 修改会话配置并在服务端执行一次 SET 校验配置是否有效，
+// 成功后之后的每次调用都以 x-datafusion-setting 元数据带上该配置，结果缓存随之清空。
+// 值包含不可打印字符时返回 ErrInvalidMetadata，不访问服务端。
+func (c *DataFusionClient) SetSessionConfig(ctx context.Context, key, value string) error {
+	if err := validateSetting(key, value); err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("SET %s = '%s'", key, strings.ReplaceAll(value, "'", "''"))
+	if _, err := c.query(ctx, &pb.QueryRequest{Sql: stmt}); err != nil {
+		return err
+	}
+	c.settings.set(key, value)
+	// 配置可能改变查询结果，如时区
+	c.cache.clear()
+	return nil
+}
@@ -0,0 +1,129 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// settingsServer 记录每次查询携带的会话设置
+type settingsServer struct {
+	mu       sync.Mutex
+	received [][]string
+}
+
+func (s *settingsServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *settingsServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, md.Get(sessionSettingHeader))
+	return &pb.QueryResponse{}, nil
+}
+
+// last 返回最近一次查询携带的设置
+func (s *settingsServer) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.received) == 0 {
+		return ""
+	}
+	return strings.Join(s.received[len(s.received)-1], ",")
+}
+
+func TestSessionDefaultsReplayedAfterReconnect(t *testing.T) {
+	first := &settingsServer{}
+	addr, s := serve(t, "127.0.0.1:0", first.fake())
+	c := dialTestClient(t, addr,
+		WithSessionDefaults(map[string]string{
+			"datafusion.execution.time_zone":  "+08:00",
+			"datafusion.execution.batch_size": "1024",
+		}),
+		WithReconnectGrace(5*time.Second),
+	)
+	ctx := context.Background()
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if err := c.SetSessionConfig(ctx, "datafusion.execution.target_partitions", "4"); err != nil {
+		t.Fatalf("SetSessionConfig: %v", err)
+	}
+	want := "datafusion.execution.batch_size=1024,datafusion.execution.time_zone=+08:00"
+	if got := first.last(); got != want {
+		t.Fatalf("首个服务端收到 %q, want %q", got, want)
+	}
+
+	// 重启服务端，新进程没有任何会话状态
+	s.Stop()
+	waitDisconnected(t, c)
+	second := &settingsServer{}
+	serve(t, addr, second.fake())
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 2"); err != nil {
+		t.Fatalf("重连后 ExecuteQuery: %v", err)
+	}
+	want = "datafusion.execution.batch_size=1024,datafusion.execution.target_partitions=4,datafusion.execution.time_zone=+08:00"
+	if got := second.last(); got != want {
+		t.Errorf("重连后服务端收到 %q, want %q", got, want)
+	}
+}
+
+func TestSessionSettingsRejectInvalidKeys(t *testing.T) {
+	if _, err := newSessionSettings(map[string]string{"bad key": "1"}); err == nil {
+		t.Error("无效的配置项应返回错误")
+	}
+
+	srv := &settingsServer{}
+	c := newTestClient(t, srv.fake())
+	if err := c.SetSessionConfig(context.Background(), "x; DROP TABLE t", "1"); err == nil {
+		t.Error("无效的配置项应返回错误")
+	}
+	if len(srv.received) != 0 {
+		t.Errorf("无效的配置项被发往服务端")
+	}
+}
+
+func TestSessionSettingsRejectInvalidValues(t *testing.T) {
+	for _, v := range []string{"a\nb", "\x00", "上海"} {
+		if _, err := newSessionSettings(map[string]string{"datafusion.execution.time_zone": v}); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("newSessionSettings(%q) = %v, want ErrInvalidMetadata", v, err)
+		}
+	}
+	c, err := NewClient(context.Background(), "127.0.0.1:1", WithInsecure(),
+		WithSessionDefaults(map[string]string{"datafusion.execution.time_zone": "Asia/\r\nShanghai"}))
+	if err == nil {
+		c.Close()
+		t.Error("会话配置值包含换行时 NewClient 应返回错误")
+	}
+
+	srv := &settingsServer{}
+	c = newTestClient(t, srv.fake())
+	if err := c.SetSessionConfig(context.Background(), "datafusion.execution.time_zone", "+08:00\n"); !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("SetSessionConfig = %v, want ErrInvalidMetadata", err)
+	}
+	if len(srv.received) != 0 {
+		t.Errorf("无效的配置值被发往服务端")
+	}
+	if err := c.SetSessionConfig(context.Background(), "datafusion.execution.time_zone", "Asia/Shanghai"); err != nil {
+		t.Fatalf("SetSessionConfig: %v", err)
+	}
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if got, want := srv.last(), "datafusion.execution.time_zone=Asia/Shanghai"; got != want {
+		t.Errorf("服务端收到 %q, want %q", got, want)
+	}
+}
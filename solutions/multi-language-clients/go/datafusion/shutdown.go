@@ -0,0 +1,82 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// inflight 跟踪进行中的调用，关闭后拒绝新的调用
+type inflight struct {
+	mu      sync.Mutex
+	closing bool
+	wg      sync.WaitGroup
+}
+
+// begin 登记一次调用，返回的 done 在调用结束时执行一次
+func (f *inflight) begin() (done func(), err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closing {
+		return nil, ErrClientClosed
+	}
+	f.wg.Add(1)
+	var once sync.Once
+	return func() { once.Do(f.wg.Done) }, nil
+}
+
+// close 停止接受新的调用
+func (f *inflight) close() {
+	f.mu.Lock()
+	f.closing = true
+	f.mu.Unlock()
+}
+
+// closed 报告是否已停止接受新的调用
+func (f *inflight) closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closing
+}
+
+// wait 等待已登记的调用全部结束或 ctx 结束
+func (f *inflight) wait(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 停止接受新的查询，等待进行中的查询 (包括未读完的结果流) 结束后关闭连接。
+// ctx 先结束时立即关闭连接，中断剩余查询并返回 ctx 的错误。
+// 之后的调用返回 ErrClientClosed。
+func (c *DataFusionClient) Shutdown(ctx context.Context) error {
+	c.calls.close()
+	err := c.calls.wait(ctx)
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Shutdown 并发地对所有端点执行 Shutdown。
+func (p *Pool) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			errs[i] = ep.client.Shutdown(ctx)
+		}(i, ep)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// slowServer 在 release 关闭或调用方取消前阻塞每次查询
+type slowServer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func newSlowServer() *slowServer {
+	return &slowServer{started: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (s *slowServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+		return &pb.QueryResponse{Result: "done"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestShutdownWaitsForInflightQuery(t *testing.T) {
+	srv := newSlowServer()
+	c := newTestClient(t, srv.fake())
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := c.ExecuteQuery(context.Background(), "SELECT slow")
+		result <- err
+	}()
+	<-srv.started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- c.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("查询结束前 Shutdown 就返回了: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("关闭中的新查询 err = %v, want ErrClientClosed", err)
+	}
+
+	close(srv.release)
+	if err := <-result; err != nil {
+		t.Errorf("进行中的查询 err = %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	srv := newSlowServer()
+	defer close(srv.release)
+	c := newTestClient(t, srv.fake())
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := c.ExecuteQuery(context.Background(), "SELECT slow")
+		result <- err
+	}()
+	<-srv.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown err = %v, want DeadlineExceeded", err)
+	}
+	if err := <-result; err == nil {
+		t.Error("连接关闭后进行中的查询应失败")
+	}
+}
+
+func TestClosedClientRejectsCacheHits(t *testing.T) {
+	srv := &countingServer{}
+	c := newTestClient(t, srv.fake(), WithResultCache(10, time.Minute))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("err = %v, want ErrClientClosed", err)
+	}
+}
@@ -0,0 +1,196 @@
+package datafusion
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// ErrStreamClosed 表示结果流在读到 io.EOF 之前被 Close 终止。
+// 它的状态码为 Canceled，指标、日志和 Hooks.OnQueryEnd 以它记录这类查询。
+var ErrStreamClosed = status.Error(codes.Canceled, "结果流在读完前被关闭")
+
+// ResultStream 按批次迭代流式查询的结果。
+// 读取完毕或不再需要时应调用 Close 释放流。
+type ResultStream struct {
+	sql     string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	recv    func() (*RowBatch, error)
+	columns []Column
+	done    bool
+	// rows 是已收到的行数
+	rows int
+	// onEnd 在流结束时按顺序调用一次
+	onEnd []func(err error)
+	// finished 在流结束或被关闭时关闭
+	finished   chan struct{}
+	finishOnce sync.Once
+	endOnce    sync.Once
+}
+
+// NewResultStream 返回依次产出 batches 的内存结果流，主要用于测试。
+func NewResultStream(batches ...*RowBatch) *ResultStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ResultStream{
+		ctx:    ctx,
+		cancel: cancel,
+		recv: func() (*RowBatch, error) {
+			if len(batches) == 0 {
+				return nil, io.EOF
+			}
+			b := batches[0]
+			batches = batches[1:]
+			return b, nil
+		},
+		finished: make(chan struct{}),
+	}
+}
+
+// ExecuteQueryStream 执行查询并返回结果流。
+// 调用方上下文结束时会通知服务端终止查询。
+func (c *DataFusionClient) ExecuteQueryStream(ctx context.Context, sql string, opts ...QueryOption) (*ResultStream, error) {
+	ctx, err := applyQueryOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(sql); err != nil {
+		return nil, err
+	}
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = ensureRequestID(ctx)
+	parent := ctx
+	c.opts.hooks.queryStart(parent, sql)
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	active, remove := c.active.add(cancel)
+	stream, err := c.rpc.QueryStream(withActiveQuery(ctx, active), &pb.QueryRequest{Sql: sql})
+	if err != nil {
+		remove()
+		cancel()
+		done()
+		err = newQueryError(ctx, sql, err)
+		dur := time.Since(start)
+		c.metrics.observe(parent, methodStream, err, dur)
+		c.logQuery(parent, methodStream, sql, err, dur)
+		c.opts.hooks.queryEnd(parent, sql, 0, err, dur)
+		return nil, err
+	}
+
+	s := &ResultStream{
+		sql:    sql,
+		ctx:    ctx,
+		cancel: cancel,
+		recv: func() (*RowBatch, error) {
+			msg, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			return &RowBatch{
+				Columns: columnsFromPB(msg.GetColumns()),
+				Rows:    rowsFromPB(msg.GetRows()),
+			}, nil
+		},
+		finished: make(chan struct{}),
+	}
+	s.onEnd = append(s.onEnd, func(err error) {
+		remove()
+		dur := time.Since(start)
+		c.metrics.observe(parent, methodStream, err, dur)
+		c.logQuery(parent, methodStream, sql, err, dur)
+		c.opts.hooks.queryEnd(parent, sql, s.rows, err, dur)
+		done()
+	})
+	go func() {
+		select {
+		case <-parent.Done():
+		case <-s.finished:
+			// Next 因调用方上下文结束而返回时流已结束，仍需通知服务端
+			if parent.Err() == nil {
+				return
+			}
+		}
+		if header, err := stream.Header(); err == nil {
+			if id, ok := active.claimCancel(queryIDFromHeader(header)); ok {
+				c.cancelOnServer(id)
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Next 返回下一批行，流结束时返回 io.EOF。
+// 上下文被取消时返回上下文的错误，其他失败返回 *QueryError。
+func (s *ResultStream) Next() (*RowBatch, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	batch, err := s.recv()
+	if err != nil {
+		s.done = true
+		// finish 会取消 s.ctx，需先判断流是否因上下文结束而中止
+		ctxErr := s.ctx.Err()
+		s.finish()
+		if ctxErr != nil && err != io.EOF {
+			// 由监听调用方上下文的协程负责通知服务端
+			s.end(ctxErr)
+			return nil, ctxErr
+		}
+		if err == io.EOF {
+			s.end(nil)
+			return nil, io.EOF
+		}
+		err = newQueryError(s.ctx, s.sql, err)
+		s.end(err)
+		return nil, err
+	}
+	if s.columns == nil && batch.Columns != nil {
+		s.columns = batch.Columns
+	}
+	s.rows += len(batch.Rows)
+	return &RowBatch{Columns: s.columns, Rows: batch.Rows}, nil
+}
+
+// Columns 返回已收到的列定义，首个批次到达前为 nil。
+func (s *ResultStream) Columns() []Column {
+	return s.columns
+}
+
+// RequestID 返回流式调用发送的 x-request-id。
+func (s *ResultStream) RequestID() string {
+	return requestID(s.ctx)
+}
+
+// Close 终止结果流。未读到 io.EOF 就关闭的流按 ErrStreamClosed 记录为被调用方取消。
+func (s *ResultStream) Close() error {
+	s.done = true
+	s.finish()
+	s.end(ErrStreamClosed)
+	return nil
+}
+
+// end 在流结束时执行一次 onEnd 回调
+func (s *ResultStream) end(err error) {
+	s.endOnce.Do(func() {
+		for _, fn := range s.onEnd {
+			fn(err)
+		}
+	})
+}
+
+func (s *ResultStream) finish() {
+	s.finishOnce.Do(func() {
+		close(s.finished)
+		s.cancel()
+	})
+}
@@ -0,0 +1,155 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"datafusion-client/pb"
+)
+
+// cancelServer 为每个查询分配 ID 并在响应头中返回，发送一批行后阻塞到调用方取消，
+// 记录收到的 CancelQuery 请求
+type cancelServer struct {
+	mu       sync.Mutex
+	next     int
+	canceled []string
+	// cancelErr 非空时 CancelQuery 返回该错误
+	cancelErr error
+	started   chan string
+}
+
+func (s *cancelServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+		prepare:      s.prepare,
+		execPrepared: s.execPrepared,
+		executeBatch: s.executeBatch,
+		openCursor:   s.openCursor,
+		queryStream:  s.queryStream,
+		cancelQuery:  s.cancelQuery,
+	}
+}
+
+func newCancelServer() *cancelServer {
+	return &cancelServer{started: make(chan string, 16)}
+}
+
+func (s *cancelServer) assignID(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	s.next++
+	id := fmt.Sprintf("q-%d", s.next)
+	s.mu.Unlock()
+	if err := grpc.SendHeader(ctx, metadata.Pairs(queryIDHeader, id)); err != nil {
+		return "", err
+	}
+	s.started <- id
+	return id, nil
+}
+
+func (s *cancelServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	if _, err := s.assignID(ctx); err != nil {
+		return nil, err
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *cancelServer) prepare(context.Context, *pb.PrepareRequest) (*pb.PrepareResponse, error) {
+	return &pb.PrepareResponse{Handle: "h-1"}, nil
+}
+
+func (s *cancelServer) execPrepared(ctx context.Context, _ *pb.ExecPreparedRequest) (*pb.QueryResponse, error) {
+	return s.executeQuery(ctx, nil)
+}
+
+func (s *cancelServer) executeBatch(ctx context.Context, _ *pb.BatchRequest) (*pb.BatchResponse, error) {
+	if _, err := s.assignID(ctx); err != nil {
+		return nil, err
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *cancelServer) openCursor(ctx context.Context, _ *pb.OpenCursorRequest) (*pb.Page, error) {
+	if _, err := s.assignID(ctx); err != nil {
+		return nil, err
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *cancelServer) queryStream(req *pb.QueryRequest, stream pb.DataFusion_QueryStreamServer) error {
+	if _, err := s.assignID(stream.Context()); err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.RowBatch{
+		Columns: []*pb.Column{{Name: "x", DataType: "Int64"}},
+		Rows:    []*pb.Row{{Values: []*pb.Value{mustPBValue(int64(1))}}},
+	}); err != nil {
+		return err
+	}
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func (s *cancelServer) cancelQuery(ctx context.Context, req *pb.CancelQueryRequest) (*pb.CancelQueryResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.canceled = append(s.canceled, req.GetQueryId())
+	return &pb.CancelQueryResponse{}, s.cancelErr
+}
+
+// waitCanceled 等待服务端收到 n 个 CancelQuery 并返回它们的查询 ID
+func (s *cancelServer) waitCanceled(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		got := append([]string(nil), s.canceled...)
+		s.mu.Unlock()
+		if len(got) >= n {
+			return got
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("服务端未在超时前收到 %d 个 CancelQuery", n)
+	return nil
+}
+
+func TestStreamContextCancelFinishesStream(t *testing.T) {
+	srv := newCancelServer()
+	c := newTestClient(t, srv.fake())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := c.ExecuteQueryStream(ctx, "SELECT x FROM t")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	if _, err := s.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	cancel()
+	if _, err := s.Next(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("取消后 Next err = %v, want context.Canceled", err)
+	}
+
+	select {
+	case <-s.finished:
+	default:
+		t.Fatal("上下文结束后流未被释放")
+	}
+	if _, err := s.Next(); err != io.EOF {
+		t.Errorf("流结束后 Next err = %v, want io.EOF", err)
+	}
+	if got := srv.waitCanceled(t, 1); got[0] != "q-1" {
+		t.Errorf("服务端收到的 CancelQuery = %v, want [q-1]", got)
+	}
+}
@@ -0,0 +1,260 @@
+package datafusion
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 结构体字段标签名
+const structTag = "datafusion"
+
+// QueryInto 执行查询并将结果写入 dest，dest 必须是 *[]T 或 *[]*T，T 为结构体。
+// 字段映射规则见 QueryResponse.ScanAll。
+func (c *DataFusionClient) QueryInto(ctx context.Context, sql string, dest any) error {
+	resp, err := c.ExecuteQuery(ctx, sql)
+	if err != nil {
+		return err
+	}
+	return resp.ScanAll(dest)
+}
+
+// ScanAll 将所有行写入 dest，dest 必须是 *[]T 或 *[]*T，T 为结构体，原有元素会被替换。
+//
+// 列按字段标签 `datafusion:"column_name"` 匹配，没有标签的导出字段按字段名
+// 不区分大小写匹配，标签为 "-" 的字段被忽略。嵌入结构体的字段视为外层字段，
+// 但未导出的嵌入结构体指针无法分配，其字段对应的列出现在结果中时返回错误。
+// *T 类型的字段可以接收 NULL，其余字段遇到 NULL 报错 (sql.Scanner 除外)。
+//
+// 结果中有列找不到对应字段时返回错误；标签带 required 选项
+// (如 `datafusion:"id,required"`) 的字段在结果中没有对应列时同样返回错误。
+func (r *QueryResponse) ScanAll(dest any) error {
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() || dv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("目标必须是指向结构体切片的指针，实际为 %T", dest)
+	}
+	slice := dv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("目标必须是指向结构体切片的指针，实际为 %T", dest)
+	}
+
+	fields, err := structFields(structType)
+	if err != nil {
+		return err
+	}
+	plan, err := matchColumns(r.Columns, fields, structType)
+	if err != nil {
+		return err
+	}
+
+	out := reflect.MakeSlice(slice.Type(), len(r.Rows), len(r.Rows))
+	for i, row := range r.Rows {
+		sv := reflect.New(structType).Elem()
+		for col, f := range plan {
+			var v any
+			if col < len(row) {
+				v = row[col]
+			}
+			fv, err := fieldByIndex(sv, f.index)
+			if err == nil {
+				err = scanField(fv, v)
+			}
+			if err != nil {
+				return fmt.Errorf("扫描第 %d 行的列 %s 到字段 %s 失败: %w", i+1, columnName(r.Columns, col), f.path, err)
+			}
+		}
+		if elemType.Kind() == reflect.Pointer {
+			sv = sv.Addr()
+		}
+		out.Index(i).Set(sv)
+	}
+	slice.Set(out)
+	return nil
+}
+
+// structField 是可接收列值的结构体字段
+type structField struct {
+	// name 是标签中的列名，未打标签时为字段名
+	name   string
+	tagged bool
+	// required 表示结果中必须有对应的列
+	required bool
+	index    []int
+	// path 是用于错误信息的字段路径，如 User.Address.City
+	path  string
+	depth int
+}
+
+// structFields 展开 t 及其嵌入结构体的字段，外层字段优先
+func structFields(t reflect.Type) ([]structField, error) {
+	var fields []structField
+	var walk func(t reflect.Type, index []int, path string, depth int)
+	walk = func(t reflect.Type, index []int, path string, depth int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get(structTag)
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(append([]int(nil), index...), i)
+
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				walk(ft, idx, path+"."+sf.Name, depth+1)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			tagged := name != ""
+			if !tagged {
+				name = sf.Name
+			}
+			fields = append(fields, structField{
+				name:     name,
+				tagged:   tagged,
+				required: opts == "required",
+				index:    idx,
+				path:     path + "." + sf.Name,
+				depth:    depth,
+			})
+		}
+	}
+	walk(t, nil, t.Name(), 0)
+
+	// 同名字段只保留最外层的一个，同一层出现两次时无法决定
+	byName := make(map[string]int, len(fields))
+	kept := fields[:0]
+	for _, f := range fields {
+		key := strings.ToLower(f.name)
+		if j, ok := byName[key]; ok {
+			if kept[j].depth == f.depth {
+				return nil, fmt.Errorf("字段 %s 与 %s 对应同一列 %s", kept[j].path, f.path, f.name)
+			}
+			if kept[j].depth < f.depth {
+				continue
+			}
+			kept[j] = f
+			continue
+		}
+		byName[key] = len(kept)
+		kept = append(kept, f)
+	}
+	return kept, nil
+}
+
+// matchColumns 为每一列找到对应的字段，返回以列下标为键的映射
+func matchColumns(columns []Column, fields []structField, t reflect.Type) (map[int]structField, error) {
+	plan := make(map[int]structField, len(columns))
+	matched := make(map[string]bool, len(fields))
+	for i, col := range columns {
+		f, ok := findField(fields, col.Name)
+		if !ok {
+			return nil, fmt.Errorf("列 %q 在 %s 中没有对应的字段", col.Name, t)
+		}
+		plan[i] = f
+		matched[f.path] = true
+	}
+	for _, f := range fields {
+		if f.required && !matched[f.path] {
+			return nil, fmt.Errorf("字段 %s 需要的列 %q 不在结果中", f.path, f.name)
+		}
+	}
+	return plan, nil
+}
+
+// findField 优先按标签精确匹配，其次不区分大小写匹配
+func findField(fields []structField, column string) (structField, bool) {
+	for _, f := range fields {
+		if f.tagged && f.name == column {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, column) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+// fieldByIndex 与 reflect.Value.FieldByIndex 相同，但会为途经的空指针分配内存。
+// 未导出的嵌入指针无法通过反射赋值，为空时返回错误
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	var embedded string
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("无法为未导出的嵌入指针字段 %s 分配内存", embedded)
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		embedded = v.Type().Field(x).Name
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// scanField 将 v 写入字段，*T 字段以 nil 表示 NULL
+func scanField(fv reflect.Value, v any) error {
+	if fv.Kind() == reflect.Pointer {
+		if v == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		p := reflect.New(fv.Type().Elem())
+		if err := scanField(p.Elem(), v); err != nil {
+			return err
+		}
+		fv.Set(p)
+		return nil
+	}
+
+	if fv.Addr().Type().Implements(scannerType) {
+		return scanValue(fv.Addr().Interface(), v)
+	}
+	// scanValue 不支持的整数和浮点宽度
+	if x, ok := v.(int64); ok {
+		switch fv.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32:
+			if fv.OverflowInt(x) {
+				return fmt.Errorf("%d 超出 %s 的范围", x, fv.Type())
+			}
+			fv.SetInt(x)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x < 0 || fv.OverflowUint(uint64(x)) {
+				return fmt.Errorf("%d 超出 %s 的范围", x, fv.Type())
+			}
+			fv.SetUint(uint64(x))
+			return nil
+		}
+	}
+	if fv.Kind() == reflect.Float32 {
+		switch x := v.(type) {
+		case float64:
+			fv.SetFloat(x)
+			return nil
+		case int64:
+			fv.SetFloat(float64(x))
+			return nil
+		}
+	}
+	return scanValue(fv.Addr().Interface(), v)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
@@ -0,0 +1,276 @@
+package datafusion
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"datafusion-client/pb"
+)
+
+// scanResponse 构造带结构化结果的响应
+func scanResponse(names []string, rows ...Row) *QueryResponse {
+	cols := make([]Column, len(names))
+	for i, name := range names {
+		cols[i] = Column{Name: name}
+	}
+	return &QueryResponse{Columns: cols, Rows: rows}
+}
+
+type scanUser struct {
+	Name string
+	Age  int
+	City *string
+}
+
+func TestScanAllNullablePointer(t *testing.T) {
+	resp := scanResponse([]string{"name", "age", "city"},
+		Row{"alice", int64(30), "Paris"},
+		Row{"bob", int64(25), nil},
+	)
+	var users []scanUser
+	if err := resp.ScanAll(&users); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("len = %d, want 2", len(users))
+	}
+	if u := users[0]; u.Name != "alice" || u.Age != 30 || u.City == nil || *u.City != "Paris" {
+		t.Errorf("users[0] = %+v", u)
+	}
+	if u := users[1]; u.Name != "bob" || u.Age != 25 || u.City != nil {
+		t.Errorf("users[1] = %+v, want City = nil", u)
+	}
+}
+
+func TestScanAllTags(t *testing.T) {
+	type tagged struct {
+		ID      int64  `datafusion:"user_id,required"`
+		Display string `datafusion:"display_name"`
+		Skipped string `datafusion:"-"`
+		Email   string
+		hidden  string
+	}
+	resp := scanResponse([]string{"user_id", "display_name", "EMAIL"}, Row{int64(7), "Alice", "a@example.com"})
+	var got []tagged
+	if err := resp.ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	want := tagged{ID: 7, Display: "Alice", Email: "a@example.com"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// 标签为 "-" 的字段不参与匹配
+	if err := scanResponse([]string{"skipped"}).ScanAll(&got); err == nil || !strings.Contains(err.Error(), `"skipped"`) {
+		t.Errorf("err = %v, want 列没有对应字段", err)
+	}
+	// required 字段缺少对应列
+	err := scanResponse([]string{"display_name"}).ScanAll(&got)
+	if err == nil || !strings.Contains(err.Error(), "tagged.ID") {
+		t.Errorf("err = %v, want 提及 tagged.ID", err)
+	}
+}
+
+type scanBase struct {
+	ID int64
+}
+
+// Audit 需要导出，嵌入的指针才能被分配
+type Audit struct {
+	CreatedBy string
+}
+
+type scanLocated struct {
+	City string
+}
+
+func TestScanAllEmbedded(t *testing.T) {
+	type account struct {
+		scanBase
+		*Audit
+		Name string
+	}
+	resp := scanResponse([]string{"id", "createdby", "name"}, Row{int64(1), "root", "a"})
+	var got []*account
+	if err := resp.ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("len = %d, want 1", len(got))
+	}
+	a := got[0]
+	if a.ID != 1 || a.Name != "a" || a.Audit == nil || a.CreatedBy != "root" {
+		t.Errorf("got %+v", a)
+	}
+}
+
+func TestScanAllEmbeddedOuterFieldWins(t *testing.T) {
+	type outer struct {
+		scanBase
+		ID string
+	}
+	var got []outer
+	if err := scanResponse([]string{"id"}, Row{"outer"}).ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if got[0].ID != "outer" || got[0].scanBase.ID != 0 {
+		t.Errorf("got %+v, want 外层字段优先", got[0])
+	}
+}
+
+func TestScanAllEmbeddedConflict(t *testing.T) {
+	type other struct {
+		ID int64
+	}
+	type conflict struct {
+		scanBase
+		other
+	}
+	var got []conflict
+	err := scanResponse([]string{"id"}, Row{int64(1)}).ScanAll(&got)
+	if err == nil || !strings.Contains(err.Error(), "同一列") {
+		t.Errorf("err = %v, want 同层字段冲突", err)
+	}
+}
+
+func TestScanAllUnexportedEmbeddedPointer(t *testing.T) {
+	type located struct {
+		*scanLocated
+		Name string
+	}
+	var got []located
+	err := scanResponse([]string{"name", "city"}, Row{"a", "Paris"}).ScanAll(&got)
+	if err == nil {
+		t.Fatal("未导出的嵌入指针应返回错误而不是 panic")
+	}
+	if !strings.Contains(err.Error(), "scanLocated") {
+		t.Errorf("err = %v, want 提及字段 scanLocated", err)
+	}
+
+	// 不涉及嵌入指针的列照常扫描
+	if err := scanResponse([]string{"name"}, Row{"a"}).ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if got[0].Name != "a" || got[0].scanLocated != nil {
+		t.Errorf("got %+v", got[0])
+	}
+}
+
+func TestScanAllNulls(t *testing.T) {
+	type row struct {
+		Note sql.NullString
+		Age  *int32
+		Any  any
+	}
+	resp := scanResponse([]string{"note", "age", "any"},
+		Row{nil, nil, nil},
+		Row{"hi", int64(3), int64(4)},
+	)
+	var got []row
+	if err := resp.ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if got[0].Note.Valid || got[0].Age != nil || got[0].Any != nil {
+		t.Errorf("got[0] = %+v, want 全部为 NULL", got[0])
+	}
+	if !got[1].Note.Valid || got[1].Note.String != "hi" || got[1].Age == nil || *got[1].Age != 3 || got[1].Any != int64(4) {
+		t.Errorf("got[1] = %+v", got[1])
+	}
+
+	// NULL 写入非指针字段
+	var users []scanUser
+	err := scanResponse([]string{"name", "age"}, Row{"a", nil}).ScanAll(&users)
+	if err == nil || !strings.Contains(err.Error(), "第 1 行") || !strings.Contains(err.Error(), "scanUser.Age") {
+		t.Errorf("err = %v, want 提及行号和字段", err)
+	}
+}
+
+func TestScanAllTypeMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *QueryResponse
+		want string
+	}{
+		{"字符串写入整数", scanResponse([]string{"age"}, Row{"thirty"}), "scanUser.Age"},
+		{"整数写入字符串", scanResponse([]string{"name"}, Row{int64(1)}), "scanUser.Name"},
+		{"指针字段", scanResponse([]string{"city"}, Row{true}), "scanUser.City"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []scanUser
+			err := tt.resp.ScanAll(&users)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want 提及 %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAllIntegerRange(t *testing.T) {
+	type row struct {
+		Small int8
+		Count uint16
+		Ratio float32
+	}
+	var got []row
+	if err := scanResponse([]string{"small", "count", "ratio"}, Row{int64(-5), int64(600), int64(2)}).ScanAll(&got); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if got[0] != (row{Small: -5, Count: 600, Ratio: 2}) {
+		t.Errorf("got %+v", got[0])
+	}
+	for _, v := range []Row{{int64(200), int64(0), 0.5}, {int64(0), int64(-1), 0.5}} {
+		if err := scanResponse([]string{"small", "count", "ratio"}, v).ScanAll(&got); err == nil || !strings.Contains(err.Error(), "超出") {
+			t.Errorf("row %v: err = %v, want 超出范围", v, err)
+		}
+	}
+}
+
+func TestScanAllInvalidDest(t *testing.T) {
+	resp := scanResponse([]string{"name"}, Row{"a"})
+	var users []scanUser
+	var names []string
+	for _, dest := range []any{nil, users, &names, new(scanUser)} {
+		if err := resp.ScanAll(dest); err == nil {
+			t.Errorf("ScanAll(%T) 应返回错误", dest)
+		}
+	}
+}
+
+func TestScanAllReplacesElements(t *testing.T) {
+	users := []scanUser{{Name: "old"}, {Name: "old"}, {Name: "old"}}
+	if err := scanResponse([]string{"name"}, Row{"new"}).ScanAll(&users); err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if len(users) != 1 || users[0].Name != "new" {
+		t.Errorf("users = %+v, want 原有元素被替换", users)
+	}
+}
+
+// scanServer 返回固定两行用户数据的服务端
+func scanServer() *fakeServer {
+	return &fakeServer{
+		executeQuery: func(context.Context, *pb.QueryRequest) (*pb.QueryResponse, error) {
+			return &pb.QueryResponse{
+				Columns: []*pb.Column{{Name: "name", DataType: "Utf8"}, {Name: "age", DataType: "Int64"}, {Name: "city", DataType: "Utf8", Nullable: true}},
+				Rows: []*pb.Row{
+					{Values: []*pb.Value{mustPBValue("alice"), mustPBValue(int64(30)), mustPBValue("Paris")}},
+					{Values: []*pb.Value{mustPBValue("bob"), mustPBValue(int64(25)), mustPBValue(nil)}},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestQueryInto(t *testing.T) {
+	c := newTestClient(t, scanServer())
+	var users []*scanUser
+	if err := c.QueryInto(context.Background(), "SELECT name, age, city FROM users", &users); err != nil {
+		t.Fatalf("QueryInto: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || *users[0].City != "Paris" || users[1].City != nil {
+		t.Errorf("users = %+v", users)
+	}
+}
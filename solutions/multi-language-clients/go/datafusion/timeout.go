@@ -0,0 +1,67 @@
+package datafusion
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// statementTimeoutHeader 携带服务端强制执行的语句超时 (毫秒)
+const statementTimeoutHeader = "x-statement-timeout-ms"
+
+// WithQueryTimeout 为每次非流式查询单独设置超时。
+// 传入的上下文已有更早的截止时间时以后者为准。
+func WithQueryTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.queryTimeout = d
+	}
+}
+
+// WithStatementTimeout 让服务端在语句执行超过 d 时终止查询并返回 DeadlineExceeded。
+// 与 WithQueryTimeout 不同，即使客户端断开，查询也不会在服务端无限运行下去。
+// 超时随每次调用以 x-statement-timeout-ms 元数据发送。
+func WithStatementTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.statementTimeout = d
+	}
+}
+
+// WithQueryStatementTimeout 为本次调用覆盖 WithStatementTimeout，d 为 0 表示不限制。
+func WithQueryStatementTimeout(d time.Duration) QueryOption {
+	return func(o *queryOptions) {
+		o.statementTimeout = &d
+	}
+}
+
+// statementTimeoutMillis 将超时换算为毫秒，不足 1 毫秒按 1 毫秒发送
+func statementTimeoutMillis(d time.Duration) string {
+	if d <= 0 {
+		return "0"
+	}
+	ms := d.Milliseconds()
+	if ms == 0 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// QueryWithTimeout 在 d 内执行查询，与 WithQueryTimeout 同时生效时取更早的截止时间。
+func (c *DataFusionClient) QueryWithTimeout(ctx context.Context, sql string, d time.Duration) (*QueryResponse, error) {
+	ctx, cancel := withTimeout(ctx, d)
+	defer cancel()
+	return c.ExecuteQuery(ctx, sql)
+}
+
+// queryContext 为单次查询派生带默认超时的上下文
+func (c *DataFusionClient) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, c.opts.queryTimeout)
+}
+
+// withTimeout 在 d > 0 时派生超时上下文。
+// context.WithTimeout 不会推迟父上下文的截止时间，因此结果总是两者中更早的那个。
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
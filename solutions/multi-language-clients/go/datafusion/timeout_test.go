@@ -0,0 +1,122 @@
+package datafusion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"datafusion-client/pb"
+)
+
+// deadlineServer 记录服务端看到的剩余截止时间，没有截止时间时记为 -1
+func deadlineServer(remaining *time.Duration) *fakeServer {
+	return &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			*remaining = -1
+			if dl, ok := ctx.Deadline(); ok {
+				*remaining = time.Until(dl)
+			}
+			return &pb.QueryResponse{}, nil
+		},
+	}
+}
+
+func TestQueryWithTimeoutUsesEarliestDeadline(t *testing.T) {
+	const short, long = 300 * time.Millisecond, time.Minute
+	tests := []struct {
+		name         string
+		callerTime   time.Duration // 0 表示调用方上下文没有截止时间
+		perQuery     time.Duration
+		queryTimeout time.Duration // WithQueryTimeout，0 表示未设置
+	}{
+		{"调用方截止时间更早", short, long, 0},
+		{"单次超时更早", long, short, 0},
+		{"调用方没有截止时间", 0, short, 0},
+		{"WithQueryTimeout 更早", long, long, short},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remaining time.Duration
+			var opts []Option
+			if tt.queryTimeout > 0 {
+				opts = append(opts, WithQueryTimeout(tt.queryTimeout))
+			}
+			c := newTestClient(t, deadlineServer(&remaining), opts...)
+
+			ctx := context.Background()
+			if tt.callerTime > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerTime)
+				defer cancel()
+			}
+			if _, err := c.QueryWithTimeout(ctx, "SELECT 1", tt.perQuery); err != nil {
+				t.Fatalf("QueryWithTimeout: %v", err)
+			}
+			// 截止时间经 grpc-timeout 传给服务端，只会比 short 更短
+			if remaining <= 0 || remaining > short {
+				t.Errorf("服务端剩余时间 = %v, want (0, %v]", remaining, short)
+			}
+		})
+	}
+}
+
+func TestQueryWithTimeoutZeroKeepsCallerDeadline(t *testing.T) {
+	var remaining time.Duration
+	c := newTestClient(t, deadlineServer(&remaining))
+
+	if _, err := c.QueryWithTimeout(context.Background(), "SELECT 1", 0); err != nil {
+		t.Fatalf("QueryWithTimeout: %v", err)
+	}
+	if remaining != -1 {
+		t.Errorf("d 为 0 且调用方没有截止时间时服务端不应看到截止时间，剩余 %v", remaining)
+	}
+}
+
+func TestWithStatementTimeoutSendsHeader(t *testing.T) {
+	srv := &metadataServer{key: statementTimeoutHeader}
+	c := newTestClient(t, srv.fake(), WithStatementTimeout(1500*time.Millisecond))
+	ctx := context.Background()
+
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	stream, err := c.ExecuteQueryStream(ctx, "SELECT 2")
+	if err != nil {
+		t.Fatalf("ExecuteQueryStream: %v", err)
+	}
+	drainStream(t, stream)
+	// 单次调用的设置覆盖客户端默认值，0 表示不限制
+	if _, err := c.ExecuteQuery(ctx, "SELECT 3", WithQueryStatementTimeout(250*time.Microsecond)); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if _, err := c.ExecuteQuery(ctx, "SELECT 4", WithQueryStatementTimeout(0)); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	got := srv.snapshot()
+	want := []string{"1500", "1500", "1", "0"}
+	if len(got) != len(want) {
+		t.Fatalf("服务端收到 %d 次调用, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != 1 || got[i][0] != want[i] {
+			t.Errorf("第 %d 次调用的 %s = %q, want [%s]", i+1, statementTimeoutHeader, got[i], want[i])
+		}
+	}
+}
+
+func TestWithoutStatementTimeoutSendsNoHeader(t *testing.T) {
+	srv := &metadataServer{key: statementTimeoutHeader}
+	c := newTestClient(t, srv.fake())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 2", WithQueryStatementTimeout(2*time.Second)); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	got := srv.snapshot()
+	if len(got) != 2 || len(got[0]) != 0 || len(got[1]) != 1 || got[1][0] != "2000" {
+		t.Errorf("%s = %q, want 第一次不发送、第二次为 2000", statementTimeoutHeader, got)
+	}
+}
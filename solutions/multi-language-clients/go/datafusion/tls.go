@@ -0,0 +1,89 @@
+package datafusion
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type tlsFiles struct {
+	caFile   string
+	certFile string
+	keyFile  string
+}
+
+// WithTLSFromFiles 使用 caFile 中的 CA 证书校验服务端。
+// certFile 和 keyFile 同时给出时向服务端出示客户端证书 (mTLS)，可都留空。
+// 文件在 NewClient 中读取，读取或解析失败时 NewClient 返回错误。
+func WithTLSFromFiles(caFile, certFile, keyFile string) Option {
+	return func(o *options) {
+		o.tlsFiles = &tlsFiles{caFile: caFile, certFile: certFile, keyFile: keyFile}
+		o.tlsConfig = nil
+		o.insecure = false
+	}
+}
+
+// WithServerName 覆盖 TLS 握手使用的服务端名称 (SNI 及证书校验)。
+func WithServerName(name string) Option {
+	return func(o *options) {
+		o.serverName = name
+	}
+}
+
+// transportCredentials 根据配置构造传输层凭据
+func (o *options) transportCredentials() (credentials.TransportCredentials, error) {
+	if o.insecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	var cfg *tls.Config
+	switch {
+	case o.tlsFiles != nil:
+		var err error
+		if cfg, err = o.tlsFiles.load(); err != nil {
+			return nil, err
+		}
+	case o.tlsConfig != nil:
+		cfg = o.tlsConfig.Clone()
+	default:
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if o.serverName != "" {
+		cfg.ServerName = o.serverName
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// load 读取证书文件并构造 TLS 配置
+func (f *tlsFiles) load() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if f.caFile != "" {
+		pem, err := os.ReadFile(f.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("解析 CA 证书失败: %s 中没有有效的 PEM 证书", f.caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case f.certFile != "" && f.keyFile != "":
+		cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case f.certFile != "" || f.keyFile != "":
+		return nil, errors.New("客户端证书和私钥必须同时指定")
+	}
+	return cfg, nil
+}
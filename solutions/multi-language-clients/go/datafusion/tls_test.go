@@ -0,0 +1,201 @@
+package datafusion
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"datafusion-client/pb"
+)
+
+// selfSigned 生成对 localhost 和 127.0.0.1 有效的自签名证书，
+// 把 PEM 写入 dir 下的 <name>.crt 和 <name>.key
+func selfSigned(t *testing.T, dir, name string) (cert tls.Certificate, certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+	if cert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("加载证书失败: %v", err)
+	}
+	return cert, certFile, keyFile
+}
+
+// serveTLS 启动使用 cfg 的 TLS 服务端，返回监听地址
+func serveTLS(t *testing.T, cfg *tls.Config, srv pb.DataFusionServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(cfg)))
+	pb.RegisterDataFusionServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+// tlsInfoServer 记录服务端看到的 TLS 对端证书数量
+func tlsInfoServer(clientCerts *int) *fakeServer {
+	return &fakeServer{
+		executeQuery: func(ctx context.Context, _ *pb.QueryRequest) (*pb.QueryResponse, error) {
+			if p, ok := peer.FromContext(ctx); ok {
+				if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+					*clientCerts = len(info.State.PeerCertificates)
+				}
+			}
+			return &pb.QueryResponse{Rows: []*pb.Row{{}}}, nil
+		},
+	}
+}
+
+func TestWithTLSFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, caFile, _ := selfSigned(t, dir, "server")
+	clientCerts := -1
+	addr := serveTLS(t, &tls.Config{Certificates: []tls.Certificate{serverCert}}, tlsInfoServer(&clientCerts))
+
+	c, err := NewClient(context.Background(), addr, WithTLSFromFiles(caFile, "", ""))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	resp, err := c.ExecuteQuery(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("ExecuteQuery over TLS: %v", err)
+	}
+	if len(resp.Rows) != 1 {
+		t.Errorf("行数 = %d, want 1", len(resp.Rows))
+	}
+	if clientCerts != 0 {
+		t.Errorf("未配置客户端证书时服务端看到 %d 个客户端证书", clientCerts)
+	}
+}
+
+func TestWithTLSFromFilesMutual(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, caFile, _ := selfSigned(t, dir, "server")
+	clientCert, certFile, keyFile := selfSigned(t, dir, "client")
+	leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	if err != nil {
+		t.Fatalf("解析客户端证书失败: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+
+	clientCerts := -1
+	addr := serveTLS(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}, tlsInfoServer(&clientCerts))
+
+	c, err := NewClient(context.Background(), addr, WithTLSFromFiles(caFile, certFile, keyFile))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery over mTLS: %v", err)
+	}
+	if clientCerts != 1 {
+		t.Errorf("服务端看到 %d 个客户端证书, want 1", clientCerts)
+	}
+}
+
+func TestWithTLSFromFilesUntrustedServer(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, _, _ := selfSigned(t, dir, "server")
+	_, otherCA, _ := selfSigned(t, dir, "other")
+	addr := serveTLS(t, &tls.Config{Certificates: []tls.Certificate{serverCert}}, &fakeServer{})
+
+	c, err := NewClient(context.Background(), addr, WithTLSFromFiles(otherCA, "", ""))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err == nil {
+		t.Error("服务端证书不受信任时查询应失败")
+	}
+}
+
+func TestWithTLSFromFilesLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := selfSigned(t, dir, "client")
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                      string
+		caFile, certFile, keyFile string
+	}{
+		{"CA 文件不存在", filepath.Join(dir, "missing.crt"), "", ""},
+		{"CA 文件不是 PEM", garbage, "", ""},
+		{"只有证书没有私钥", "", certFile, ""},
+		{"只有私钥没有证书", "", "", keyFile},
+		{"私钥文件无效", "", certFile, garbage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(context.Background(), "127.0.0.1:1", WithTLSFromFiles(tt.caFile, tt.certFile, tt.keyFile))
+			if err == nil {
+				c.Close()
+				t.Fatal("NewClient 应返回错误")
+			}
+		})
+	}
+}
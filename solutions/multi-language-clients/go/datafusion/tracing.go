@@ -0,0 +1,85 @@
+package datafusion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	tracerName = "datafusion-client"
+	spanName   = "datafusion.query"
+)
+
+// WithTracing 为客户端启用 OpenTelemetry 追踪。
+//
+// gRPC 调用由 otelgrpc 记录，并通过 W3C traceparent 头传播追踪上下文；
+// 每次非流式查询 (ExecuteQuery、ExecuteQueryArrow、ExecuteBatch、预编译语句、游标等)
+// 还会额外生成一个 datafusion.query span。
+// 服务端安装 otelgrpc.NewServerHandler 后即可与客户端 span 关联：
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+//	client, err := datafusion.NewClient(ctx, "localhost:50051",
+//		datafusion.WithInsecure(),
+//		datafusion.WithTracing(tp),
+//	)
+//
+//	// 服务端
+//	srv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+func otelClientOptions(tp trace.TracerProvider) []otelgrpc.Option {
+	return []otelgrpc.Option{
+		otelgrpc.WithTracerProvider(tp),
+		otelgrpc.WithPropagators(propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		)),
+	}
+}
+
+// sqlHash 返回 SQL 的短哈希，用于在追踪和日志中标识查询而不暴露原文
+func sqlHash(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:8])
+}
+
+// startSpan 为一次查询创建 span
+func (c *DataFusionClient) startSpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("datafusion.sql_hash", sqlHash(sql))),
+	)
+}
+
+// endSpan 记录查询结果并结束 span
+func endSpan(span trace.Span, rows int, err error, dur time.Duration) {
+	span.SetAttributes(
+		attribute.Int("datafusion.rows", rows),
+		attribute.Int64("datafusion.duration_ms", dur.Milliseconds()),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
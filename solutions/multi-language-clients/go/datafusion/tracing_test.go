@@ -0,0 +1,198 @@
+package datafusion
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"datafusion-client/pb"
+)
+
+// spanRecorder 是内存中的 TracerProvider，记录所有创建的 span
+type spanRecorder struct {
+	embedded.TracerProvider
+
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{r: r}
+}
+
+// named 返回名称为 name 的已结束 span
+func (r *spanRecorder) named(name string) []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*recordedSpan
+	for _, s := range r.spans {
+		if s.name == name && s.ended {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	r *spanRecorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	traceID := trace.SpanContextFromContext(ctx).TraceID()
+	if !traceID.IsValid() {
+		rand.Read(traceID[:])
+	}
+	var spanID trace.SpanID
+	rand.Read(spanID[:])
+
+	s := &recordedSpan{
+		name:  name,
+		attrs: map[attribute.Key]attribute.Value{},
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	s.SetAttributes(cfg.Attributes()...)
+	t.r.mu.Lock()
+	t.r.spans = append(t.r.spans, s)
+	t.r.mu.Unlock()
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+type recordedSpan struct {
+	noop.Span
+
+	mu     sync.Mutex
+	name   string
+	sc     trace.SpanContext
+	attrs  map[attribute.Key]attribute.Value
+	status otelcodes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.sc }
+func (s *recordedSpan) IsRecording() bool              { return true }
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code otelcodes.Code, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+// traceServer 记录收到的 traceparent 头
+type traceServer struct {
+	mu          sync.Mutex
+	traceparent []string
+}
+
+func (s *traceServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+	}
+}
+
+func (s *traceServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.traceparent = append(s.traceparent, md.Get("traceparent")...)
+	s.mu.Unlock()
+	if req.GetSql() == "SELECT broken" {
+		return nil, status.Error(codes.InvalidArgument, "语法错误")
+	}
+	return &pb.QueryResponse{Rows: []*pb.Row{{}, {}}}, nil
+}
+
+func TestTracingSpanPerQuery(t *testing.T) {
+	rec := &spanRecorder{}
+	srv := &traceServer{}
+	c := newTestClient(t, srv.fake(), WithTracing(rec))
+
+	ctx := context.Background()
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if _, err := c.ExecuteQuery(ctx, "SELECT broken"); err == nil {
+		t.Fatal("ExecuteQuery(SELECT broken) 应返回错误")
+	}
+
+	spans := rec.named(spanName)
+	if len(spans) != 2 {
+		t.Fatalf("%s span 数量 = %d, want 2", spanName, len(spans))
+	}
+	ok, failed := spans[0], spans[1]
+	if got := ok.attrs["datafusion.sql_hash"].AsString(); got != sqlHash("SELECT 1") {
+		t.Errorf("sql_hash = %q, want %q", got, sqlHash("SELECT 1"))
+	}
+	if got := ok.attrs["datafusion.rows"].AsInt64(); got != 2 {
+		t.Errorf("rows = %d, want 2", got)
+	}
+	if ok.status == otelcodes.Error {
+		t.Error("成功查询的 span 不应标记为错误")
+	}
+	if failed.status != otelcodes.Error {
+		t.Errorf("失败查询的 span 状态 = %v, want Error", failed.status)
+	}
+}
+
+func TestTracingPropagatesAcrossGRPC(t *testing.T) {
+	rec := &spanRecorder{}
+	srv := &traceServer{}
+	c := newTestClient(t, srv.fake(), WithTracing(rec))
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	spans := rec.named(spanName)
+	if len(spans) != 1 {
+		t.Fatalf("%s span 数量 = %d, want 1", spanName, len(spans))
+	}
+	if len(srv.traceparent) != 1 {
+		t.Fatalf("服务端收到的 traceparent = %v, want 1 个", srv.traceparent)
+	}
+	traceID := spans[0].sc.TraceID().String()
+	if !strings.Contains(srv.traceparent[0], traceID) {
+		t.Errorf("traceparent %q 不属于追踪 %s", srv.traceparent[0], traceID)
+	}
+}
+
+func TestTracingDisabledSendsNoTraceparent(t *testing.T) {
+	srv := &traceServer{}
+	c := newTestClient(t, srv.fake())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(srv.traceparent) != 0 {
+		t.Errorf("未启用追踪时不应发送 traceparent: %v", srv.traceparent)
+	}
+}
@@ -0,0 +1,115 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"datafusion-client/pb"
+)
+
+// ErrTxDone 表示事务已经提交或回滚。
+var ErrTxDone = errors.New("事务已结束")
+
+// Tx 是服务端的一个事务会话，事务内的语句都携带同一个会话 ID。
+// 使用完毕必须调用 Commit 或 Rollback。
+type Tx struct {
+	client    *DataFusionClient
+	sessionID string
+
+	mu   sync.Mutex
+	done bool
+}
+
+// BeginTx 在服务端开启事务。
+func (c *DataFusionClient) BeginTx(ctx context.Context) (*Tx, error) {
+	done, err := c.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureRequestID(ctx)
+	resp, err := c.rpc.BeginTransaction(ctx, &pb.BeginTransactionRequest{})
+	if err != nil {
+		return nil, newQueryError(ctx, "BEGIN", err)
+	}
+	return &Tx{client: c, sessionID: resp.GetSessionId()}, nil
+}
+
+// BeginTx 在主节点 (未区分读写时为下一个可用端点) 上开启事务，
+// 事务内的语句都发往该端点。
+func (p *Pool) BeginTx(ctx context.Context) (*Tx, error) {
+	ep, err := p.route("BEGIN", nil)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := ep.client.BeginTx(ctx)
+	ep.breaker.record(ctx, err)
+	return tx, err
+}
+
+// SessionID 返回服务端分配的会话 ID。
+func (tx *Tx) SessionID() string {
+	return tx.sessionID
+}
+
+// Query 在事务中执行查询。事务内的查询不使用结果缓存。
+func (tx *Tx) Query(ctx context.Context, sql string) (*QueryResponse, error) {
+	if tx.finished() {
+		return nil, ErrTxDone
+	}
+	return tx.client.query(ctx, &pb.QueryRequest{Sql: sql, SessionId: tx.sessionID})
+}
+
+// Exec 在事务中执行不关心结果的语句，如 INSERT。
+func (tx *Tx) Exec(ctx context.Context, sql string) error {
+	_, err := tx.Query(ctx, sql)
+	return err
+}
+
+// Commit 提交事务。无论成功与否，事务都随之结束。
+func (tx *Tx) Commit(ctx context.Context) error {
+	if !tx.finish() {
+		return ErrTxDone
+	}
+	return tx.end(ctx, "COMMIT", tx.client.rpc.CommitTransaction)
+}
+
+// Rollback 回滚事务。事务已结束时什么也不做，
+// 因此可以在开启事务后立即 defer tx.Rollback(ctx)。
+func (tx *Tx) Rollback(ctx context.Context) error {
+	if !tx.finish() {
+		return nil
+	}
+	return tx.end(ctx, "ROLLBACK", tx.client.rpc.RollbackTransaction)
+}
+
+type endTxFunc func(context.Context, *pb.EndTransactionRequest, ...grpc.CallOption) (*pb.EndTransactionResponse, error)
+
+func (tx *Tx) end(ctx context.Context, sql string, rpc endTxFunc) error {
+	ctx = ensureRequestID(ctx)
+	if _, err := rpc(ctx, &pb.EndTransactionRequest{SessionId: tx.sessionID}); err != nil {
+		return newQueryError(ctx, sql, err)
+	}
+	return nil
+}
+
+func (tx *Tx) finished() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return tx.done
+}
+
+// finish 将事务标记为结束，事务此前已结束时返回 false
+func (tx *Tx) finish() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return false
+	}
+	tx.done = true
+	return true
+}
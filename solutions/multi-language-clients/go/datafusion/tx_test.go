@@ -0,0 +1,141 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"datafusion-client/pb"
+)
+
+// txServer 为每个事务分配会话 ID，并记录各请求携带的会话 ID
+type txServer struct {
+	mu        sync.Mutex
+	begun     int
+	queries   []string // "<session>: <sql>"
+	committed []string
+	rolled    []string
+}
+
+func (s *txServer) fake() *fakeServer {
+	return &fakeServer{
+		beginTransaction: func(context.Context, *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.begun++
+			return &pb.BeginTransactionResponse{SessionId: fmt.Sprintf("s-%d", s.begun)}, nil
+		},
+		executeQuery: func(_ context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.queries = append(s.queries, req.GetSessionId()+": "+req.GetSql())
+			return &pb.QueryResponse{}, nil
+		},
+		commitTransaction: func(_ context.Context, req *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.committed = append(s.committed, req.GetSessionId())
+			return &pb.EndTransactionResponse{}, nil
+		},
+		rollbackTransaction: func(_ context.Context, req *pb.EndTransactionRequest) (*pb.EndTransactionResponse, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.rolled = append(s.rolled, req.GetSessionId())
+			return &pb.EndTransactionResponse{}, nil
+		},
+	}
+}
+
+func TestTxStatementsShareSession(t *testing.T) {
+	srv := &txServer{}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	tx, err := c.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.Exec(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if err := tx.Exec(ctx, "INSERT INTO t VALUES (2)"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	// 事务外的查询不携带会话 ID
+	if _, err := c.ExecuteQuery(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if tx.SessionID() != "s-1" {
+		t.Errorf("SessionID = %q, want s-1", tx.SessionID())
+	}
+	want := []string{"s-1: INSERT INTO t VALUES (1)", "s-1: INSERT INTO t VALUES (2)", ": SELECT 1"}
+	if fmt.Sprint(srv.queries) != fmt.Sprint(want) {
+		t.Errorf("服务端收到 %q, want %q", srv.queries, want)
+	}
+	if fmt.Sprint(srv.committed) != "[s-1]" || len(srv.rolled) != 0 {
+		t.Errorf("提交 %q、回滚 %q, want 只提交 s-1", srv.committed, srv.rolled)
+	}
+}
+
+func TestTxSeparateSessions(t *testing.T) {
+	srv := &txServer{}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	a, err := c.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	b, err := c.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if a.SessionID() == b.SessionID() {
+		t.Fatalf("两个事务的会话 ID 相同: %q", a.SessionID())
+	}
+	if err := b.Exec(ctx, "DELETE FROM t"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if err := b.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if err := a.Commit(ctx); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if fmt.Sprint(srv.rolled) != "[s-2]" || fmt.Sprint(srv.committed) != "[s-1]" {
+		t.Errorf("提交 %q、回滚 %q, want 提交 s-1、回滚 s-2", srv.committed, srv.rolled)
+	}
+}
+
+func TestTxDone(t *testing.T) {
+	srv := &txServer{}
+	c := newTestClient(t, srv.fake())
+	ctx := context.Background()
+
+	tx, err := c.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := tx.Exec(ctx, "INSERT INTO t VALUES (3)"); !errors.Is(err, ErrTxDone) {
+		t.Errorf("提交后 Exec = %v, want ErrTxDone", err)
+	}
+	if err := tx.Commit(ctx); !errors.Is(err, ErrTxDone) {
+		t.Errorf("重复 Commit = %v, want ErrTxDone", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Errorf("提交后 Rollback = %v, want nil", err)
+	}
+	if len(srv.queries) != 0 || len(srv.committed) != 1 || len(srv.rolled) != 0 {
+		t.Errorf("事务结束后不应再发送请求: 查询 %q, 提交 %q, 回滚 %q", srv.queries, srv.committed, srv.rolled)
+	}
+}
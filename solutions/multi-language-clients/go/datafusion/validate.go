@@ -0,0 +1,117 @@
+package datafusion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidSQL 是客户端校验 SQL 失败时返回的错误，可通过 errors.Is 判断。
+var ErrInvalidSQL = errors.New("SQL 校验失败")
+
+// WithClientValidation 在发送前用 ValidateSQL 检查语句，
+// 并检查预编译语句的参数个数，明显有误的语句不再发往服务端。
+func WithClientValidation() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// ValidateSQL 检查括号是否配对、字符串、引号标识符和块注释是否闭合。
+// 它不做完整的语法分析，通过校验不代表语句一定合法。
+func ValidateSQL(sql string) error {
+	_, err := scanSQL(sql)
+	return err
+}
+
+// validate 在启用客户端校验时检查 sql
+func (c *DataFusionClient) validate(sql string) error {
+	if !c.opts.validate {
+		return nil
+	}
+	return ValidateSQL(sql)
+}
+
+// validateArgs 在启用客户端校验时检查参数个数是否与占位符匹配
+func (c *DataFusionClient) validateArgs(sql string, args []any) error {
+	if !c.opts.validate {
+		return nil
+	}
+	n, err := scanSQL(sql)
+	if err != nil {
+		return err
+	}
+	if n != len(args) {
+		return fmt.Errorf("%w: 语句有 %d 个参数占位符，传入了 %d 个参数", ErrInvalidSQL, n, len(args))
+	}
+	return nil
+}
+
+// scanSQL 检查语句的结构并返回占位符 $N 中最大的 N
+func scanSQL(sql string) (placeholders int, err error) {
+	rs := []rune(sql)
+	// open 记录未闭合的左括号位置
+	var open []int
+	for i := 0; i < len(rs); i++ {
+		switch r := rs[i]; {
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			start := i
+			i += 2
+			for i+1 < len(rs) && !(rs[i] == '*' && rs[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(rs) {
+				return 0, fmt.Errorf("%w: 位置 %d 的块注释未结束", ErrInvalidSQL, start+1)
+			}
+			i++
+		case r == '\'' || r == '"':
+			start := i
+			closed := false
+			for i++; i < len(rs); i++ {
+				if rs[i] != r {
+					continue
+				}
+				// 连续两个引号表示转义
+				if i+1 < len(rs) && rs[i+1] == r {
+					i++
+					continue
+				}
+				closed = true
+				break
+			}
+			if !closed {
+				what := "字符串字面量"
+				if r == '"' {
+					what = "引号标识符"
+				}
+				return 0, fmt.Errorf("%w: 位置 %d 的%s未结束", ErrInvalidSQL, start+1, what)
+			}
+		case r == '(':
+			open = append(open, i)
+		case r == ')':
+			if len(open) == 0 {
+				return 0, fmt.Errorf("%w: 位置 %d 的右括号没有对应的左括号", ErrInvalidSQL, i+1)
+			}
+			open = open[:len(open)-1]
+		case r == '$':
+			j := i + 1
+			for j < len(rs) && rs[j] >= '0' && rs[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				if n, err := strconv.Atoi(string(rs[i+1 : j])); err == nil && n > placeholders {
+					placeholders = n
+				}
+				i = j - 1
+			}
+		}
+	}
+	if len(open) > 0 {
+		return 0, fmt.Errorf("%w: 位置 %d 的左括号未闭合", ErrInvalidSQL, open[len(open)-1]+1)
+	}
+	return placeholders, nil
+}
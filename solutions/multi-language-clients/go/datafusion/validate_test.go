@@ -0,0 +1,135 @@
+package datafusion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"datafusion-client/pb"
+)
+
+func TestValidateSQL(t *testing.T) {
+	valid := []string{
+		"SELECT 1",
+		"SELECT 'it''s', \"a\"\"b\" FROM t WHERE (a = 1 AND (b = 2))",
+		"SELECT ')' -- (\nFROM t",
+		"SELECT 1 /* ( ' */",
+		"SELECT * FROM t WHERE a = $1 AND b = $2",
+	}
+	for _, sql := range valid {
+		if err := ValidateSQL(sql); err != nil {
+			t.Errorf("ValidateSQL(%q) = %v", sql, err)
+		}
+	}
+
+	invalid := []string{
+		"SELECT 'abc",
+		"SELECT 'it''s",
+		`SELECT "col FROM t`,
+		"SELECT (1",
+		"SELECT 1)",
+		"SELECT 1 /* 未结束",
+	}
+	for _, sql := range invalid {
+		if err := ValidateSQL(sql); !errors.Is(err, ErrInvalidSQL) {
+			t.Errorf("ValidateSQL(%q) = %v, want ErrInvalidSQL", sql, err)
+		}
+	}
+}
+
+// recordingServer 记录收到的 SQL 和预编译执行次数
+type recordingServer struct {
+	mu    sync.Mutex
+	sqls  []string
+	execs int
+}
+
+func (s *recordingServer) fake() *fakeServer {
+	return &fakeServer{
+		executeQuery: s.executeQuery,
+		prepare:      s.prepare,
+		execPrepared: s.execPrepared,
+	}
+}
+
+func (s *recordingServer) executeQuery(ctx context.Context, req *pb.QueryRequest) (*pb.QueryResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sqls = append(s.sqls, req.GetSql())
+	return &pb.QueryResponse{}, nil
+}
+
+func (s *recordingServer) prepare(ctx context.Context, req *pb.PrepareRequest) (*pb.PrepareResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sqls = append(s.sqls, req.GetSql())
+	return &pb.PrepareResponse{Handle: "h-1"}, nil
+}
+
+func (s *recordingServer) execPrepared(ctx context.Context, req *pb.ExecPreparedRequest) (*pb.QueryResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.execs++
+	return &pb.QueryResponse{}, nil
+}
+
+func TestClientValidationRejectsUnclosedQuote(t *testing.T) {
+	srv := &recordingServer{}
+	c := newTestClient(t, srv.fake(), WithClientValidation())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 'abc"); !errors.Is(err, ErrInvalidSQL) {
+		t.Fatalf("err = %v, want ErrInvalidSQL", err)
+	}
+	if len(srv.sqls) != 0 {
+		t.Errorf("无效语句被发往服务端: %v", srv.sqls)
+	}
+}
+
+func TestClientValidationParameterCount(t *testing.T) {
+	srv := &recordingServer{}
+	c := newTestClient(t, srv.fake(), WithClientValidation())
+	ctx := context.Background()
+
+	stmt, err := c.Prepare(ctx, "SELECT * FROM t WHERE a = $1 AND b = $2")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if _, err := stmt.Query(ctx, 1); !errors.Is(err, ErrInvalidSQL) {
+		t.Fatalf("参数个数不匹配时 err = %v, want ErrInvalidSQL", err)
+	}
+	if srv.execs != 0 {
+		t.Errorf("参数个数不匹配的语句被执行了 %d 次", srv.execs)
+	}
+	if _, err := stmt.Query(ctx, 1, "x"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if srv.execs != 1 {
+		t.Errorf("执行次数 = %d, want 1", srv.execs)
+	}
+}
+
+func TestClientValidationPassesValidSQLUntouched(t *testing.T) {
+	srv := &recordingServer{}
+	c := newTestClient(t, srv.fake(), WithClientValidation())
+
+	sql := "  SELECT 'a  b' -- 注释\n FROM t;"
+	if _, err := c.ExecuteQuery(context.Background(), sql); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+	if len(srv.sqls) != 1 || srv.sqls[0] != sql {
+		t.Errorf("服务端收到 %q, want %q", srv.sqls, sql)
+	}
+}
+
+func TestClientValidationDisabledByDefault(t *testing.T) {
+	srv := &recordingServer{}
+	c := newTestClient(t, srv.fake())
+
+	if _, err := c.ExecuteQuery(context.Background(), "SELECT 'abc"); err != nil {
+		t.Fatalf("未启用校验时 err = %v", err)
+	}
+	if len(srv.sqls) != 1 {
+		t.Errorf("服务端收到 %v", srv.sqls)
+	}
+}
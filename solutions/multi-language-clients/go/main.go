@@ -3,26 +3,46 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"os"
+	"strings"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"datafusion-client/datafusion"
 )
 
-// 简化的 gRPC 客户端示例
-// 注意：实际使用时需要生成对应的 protobuf 代码
+// gRPC 客户端示例
+// protobuf 代码位于 pb/，可通过 make proto 重新生成
+
+// 分隔线宽度
+const separatorWidth = 50
+
+// printSeparator 输出一行分隔线
+func printSeparator(w io.Writer) {
+	fmt.Fprintln(w, strings.Repeat("=", separatorWidth))
+}
 
 func main() {
 	// 连接到服务
-	conn, err := grpc.Dial("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	client, err := datafusion.NewClient(context.Background(), "localhost:50051",
+		datafusion.WithInsecure(),
+		datafusion.WithUserAgent("datafusion-go-example"),
+		datafusion.WithQueryTimeout(10*time.Second),
+		datafusion.WithLogger(slog.Default()),
+	)
 	if err != nil {
 		log.Fatalf("连接失败: %v", err)
 	}
-	defer conn.Close()
-
-	// 创建客户端
-	// client := pb.NewDataFusionClient(conn)
+	defer func() {
+		// 等待进行中的查询结束后再关闭连接
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Shutdown(ctx); err != nil {
+			log.Printf("关闭客户端失败: %v", err)
+		}
+	}()
 
 	// 示例查询
 	queries := []string{
@@ -31,26 +51,26 @@ func main() {
 		"SELECT city, COUNT(*) as user_count FROM users GROUP BY city",
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// 一次往返执行全部查询
+	results, err := client.ExecuteBatch(context.Background(), queries)
+	if err != nil {
+		log.Fatalf("批量查询失败: %v", err)
+	}
 
-	for _, sql := range queries {
-		fmt.Printf("\n%s\n", "="*50)
+	for i, sql := range queries {
+		fmt.Println()
+		printSeparator(os.Stdout)
 		fmt.Printf("查询: %s\n", sql)
-		fmt.Printf("%s\n", "="*50)
-
-		// 执行查询
-		// req := &pb.QueryRequest{Sql: sql}
-		// resp, err := client.ExecuteQuery(ctx, req)
-		// if err != nil {
-		//     log.Printf("查询失败: %v", err)
-		//     continue
-		// }
+		printSeparator(os.Stdout)
 
-		// 处理结果
-		// fmt.Printf("结果: %s\n", resp.Result)
+		if err := results[i].Err; err != nil {
+			log.Printf("查询失败: %v", err)
+			continue
+		}
 
-		// 临时输出
-		fmt.Printf("查询执行成功 (需要生成 protobuf 代码)\n")
+		// 输出结果
+		if err := datafusion.FormatResult(os.Stdout, results[i].Response, datafusion.FormatTable); err != nil {
+			log.Printf("输出结果失败: %v", err)
+		}
 	}
 }
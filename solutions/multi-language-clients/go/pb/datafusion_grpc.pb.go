@@ -0,0 +1,909 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: datafusion.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	DataFusion_ExecuteQuery_FullMethodName        = "/datafusion.DataFusion/ExecuteQuery"
+	DataFusion_ExecuteMulti_FullMethodName        = "/datafusion.DataFusion/ExecuteMulti"
+	DataFusion_QueryStream_FullMethodName         = "/datafusion.DataFusion/QueryStream"
+	DataFusion_Prepare_FullMethodName             = "/datafusion.DataFusion/Prepare"
+	DataFusion_ExecPrepared_FullMethodName        = "/datafusion.DataFusion/ExecPrepared"
+	DataFusion_ExecuteBatch_FullMethodName        = "/datafusion.DataFusion/ExecuteBatch"
+	DataFusion_ListTables_FullMethodName          = "/datafusion.DataFusion/ListTables"
+	DataFusion_DescribeTable_FullMethodName       = "/datafusion.DataFusion/DescribeTable"
+	DataFusion_ListMembers_FullMethodName         = "/datafusion.DataFusion/ListMembers"
+	DataFusion_CancelQuery_FullMethodName         = "/datafusion.DataFusion/CancelQuery"
+	DataFusion_BeginTransaction_FullMethodName    = "/datafusion.DataFusion/BeginTransaction"
+	DataFusion_CommitTransaction_FullMethodName   = "/datafusion.DataFusion/CommitTransaction"
+	DataFusion_RollbackTransaction_FullMethodName = "/datafusion.DataFusion/RollbackTransaction"
+	DataFusion_OpenCursor_FullMethodName          = "/datafusion.DataFusion/OpenCursor"
+	DataFusion_FetchPage_FullMethodName           = "/datafusion.DataFusion/FetchPage"
+	DataFusion_CloseCursor_FullMethodName         = "/datafusion.DataFusion/CloseCursor"
+	DataFusion_SubmitQuery_FullMethodName         = "/datafusion.DataFusion/SubmitQuery"
+	DataFusion_GetQueryStatus_FullMethodName      = "/datafusion.DataFusion/GetQueryStatus"
+	DataFusion_FetchResult_FullMethodName         = "/datafusion.DataFusion/FetchResult"
+	DataFusion_EstimateCost_FullMethodName        = "/datafusion.DataFusion/EstimateCost"
+)
+
+// DataFusionClient is the client API for DataFusion service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DataFusionClient interface {
+	// 执行 SQL 查询并一次性返回结果
+	ExecuteQuery(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// 依次执行以分号分隔的多条语句，按顺序流式返回每条语句的结果，
+	// 某条语句失败时以该语句的错误结束流，其后的语句不再执行
+	ExecuteMulti(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (DataFusion_ExecuteMultiClient, error)
+	// 执行 SQL 查询并按批次流式返回结果
+	QueryStream(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (DataFusion_QueryStreamClient, error)
+	// 预编译带 $1、$2 占位符的语句
+	Prepare(ctx context.Context, in *PrepareRequest, opts ...grpc.CallOption) (*PrepareResponse, error)
+	// 绑定参数执行预编译语句，句柄失效时返回 NOT_FOUND
+	ExecPrepared(ctx context.Context, in *ExecPreparedRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// 在一次往返中执行多条语句，单条失败不影响其余语句
+	ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// 列出目录中的表
+	ListTables(ctx context.Context, in *ListTablesRequest, opts ...grpc.CallOption) (*ListTablesResponse, error)
+	// 返回表的列定义
+	DescribeTable(ctx context.Context, in *DescribeTableRequest, opts ...grpc.CallOption) (*DescribeTableResponse, error)
+	// 返回集群当前的成员地址
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+	// 终止正在执行的查询
+	CancelQuery(ctx context.Context, in *CancelQueryRequest, opts ...grpc.CallOption) (*CancelQueryResponse, error)
+	// 开启事务，返回的会话 ID 用于关联事务内的语句
+	BeginTransaction(ctx context.Context, in *BeginTransactionRequest, opts ...grpc.CallOption) (*BeginTransactionResponse, error)
+	// 提交事务
+	CommitTransaction(ctx context.Context, in *EndTransactionRequest, opts ...grpc.CallOption) (*EndTransactionResponse, error)
+	// 回滚事务
+	RollbackTransaction(ctx context.Context, in *EndTransactionRequest, opts ...grpc.CallOption) (*EndTransactionResponse, error)
+	// 执行查询并返回第一页结果
+	OpenCursor(ctx context.Context, in *OpenCursorRequest, opts ...grpc.CallOption) (*Page, error)
+	// 按续页令牌返回下一页，令牌未知或已过期时返回 NOT_FOUND
+	FetchPage(ctx context.Context, in *FetchPageRequest, opts ...grpc.CallOption) (*Page, error)
+	// 释放游标在服务端的状态
+	CloseCursor(ctx context.Context, in *CloseCursorRequest, opts ...grpc.CallOption) (*CloseCursorResponse, error)
+	// 提交查询后立即返回查询 ID，查询在服务端后台执行
+	SubmitQuery(ctx context.Context, in *SubmitQueryRequest, opts ...grpc.CallOption) (*SubmitQueryResponse, error)
+	// 返回后台查询的执行状态，查询 ID 未知时返回 NOT_FOUND
+	GetQueryStatus(ctx context.Context, in *QueryStatusRequest, opts ...grpc.CallOption) (*QueryStatusResponse, error)
+	// 返回已成功的后台查询的结果，尚未完成时返回 FAILED_PRECONDITION
+	FetchResult(ctx context.Context, in *FetchResultRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// 只生成执行计划并估算代价，不执行查询
+	EstimateCost(ctx context.Context, in *EstimateCostRequest, opts ...grpc.CallOption) (*CostEstimate, error)
+}
+
+type dataFusionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataFusionClient(cc grpc.ClientConnInterface) DataFusionClient {
+	return &dataFusionClient{cc}
+}
+
+func (c *dataFusionClient) ExecuteQuery(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, DataFusion_ExecuteQuery_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) ExecuteMulti(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (DataFusion_ExecuteMultiClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataFusion_ServiceDesc.Streams[0], DataFusion_ExecuteMulti_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataFusionExecuteMultiClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataFusion_ExecuteMultiClient interface {
+	Recv() (*QueryResponse, error)
+	grpc.ClientStream
+}
+
+type dataFusionExecuteMultiClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataFusionExecuteMultiClient) Recv() (*QueryResponse, error) {
+	m := new(QueryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataFusionClient) QueryStream(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (DataFusion_QueryStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataFusion_ServiceDesc.Streams[1], DataFusion_QueryStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataFusionQueryStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataFusion_QueryStreamClient interface {
+	Recv() (*RowBatch, error)
+	grpc.ClientStream
+}
+
+type dataFusionQueryStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataFusionQueryStreamClient) Recv() (*RowBatch, error) {
+	m := new(RowBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataFusionClient) Prepare(ctx context.Context, in *PrepareRequest, opts ...grpc.CallOption) (*PrepareResponse, error) {
+	out := new(PrepareResponse)
+	err := c.cc.Invoke(ctx, DataFusion_Prepare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) ExecPrepared(ctx context.Context, in *ExecPreparedRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, DataFusion_ExecPrepared_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, DataFusion_ExecuteBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) ListTables(ctx context.Context, in *ListTablesRequest, opts ...grpc.CallOption) (*ListTablesResponse, error) {
+	out := new(ListTablesResponse)
+	err := c.cc.Invoke(ctx, DataFusion_ListTables_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) DescribeTable(ctx context.Context, in *DescribeTableRequest, opts ...grpc.CallOption) (*DescribeTableResponse, error) {
+	out := new(DescribeTableResponse)
+	err := c.cc.Invoke(ctx, DataFusion_DescribeTable_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
+	out := new(ListMembersResponse)
+	err := c.cc.Invoke(ctx, DataFusion_ListMembers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) CancelQuery(ctx context.Context, in *CancelQueryRequest, opts ...grpc.CallOption) (*CancelQueryResponse, error) {
+	out := new(CancelQueryResponse)
+	err := c.cc.Invoke(ctx, DataFusion_CancelQuery_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) BeginTransaction(ctx context.Context, in *BeginTransactionRequest, opts ...grpc.CallOption) (*BeginTransactionResponse, error) {
+	out := new(BeginTransactionResponse)
+	err := c.cc.Invoke(ctx, DataFusion_BeginTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) CommitTransaction(ctx context.Context, in *EndTransactionRequest, opts ...grpc.CallOption) (*EndTransactionResponse, error) {
+	out := new(EndTransactionResponse)
+	err := c.cc.Invoke(ctx, DataFusion_CommitTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) RollbackTransaction(ctx context.Context, in *EndTransactionRequest, opts ...grpc.CallOption) (*EndTransactionResponse, error) {
+	out := new(EndTransactionResponse)
+	err := c.cc.Invoke(ctx, DataFusion_RollbackTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) OpenCursor(ctx context.Context, in *OpenCursorRequest, opts ...grpc.CallOption) (*Page, error) {
+	out := new(Page)
+	err := c.cc.Invoke(ctx, DataFusion_OpenCursor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) FetchPage(ctx context.Context, in *FetchPageRequest, opts ...grpc.CallOption) (*Page, error) {
+	out := new(Page)
+	err := c.cc.Invoke(ctx, DataFusion_FetchPage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) CloseCursor(ctx context.Context, in *CloseCursorRequest, opts ...grpc.CallOption) (*CloseCursorResponse, error) {
+	out := new(CloseCursorResponse)
+	err := c.cc.Invoke(ctx, DataFusion_CloseCursor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) SubmitQuery(ctx context.Context, in *SubmitQueryRequest, opts ...grpc.CallOption) (*SubmitQueryResponse, error) {
+	out := new(SubmitQueryResponse)
+	err := c.cc.Invoke(ctx, DataFusion_SubmitQuery_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) GetQueryStatus(ctx context.Context, in *QueryStatusRequest, opts ...grpc.CallOption) (*QueryStatusResponse, error) {
+	out := new(QueryStatusResponse)
+	err := c.cc.Invoke(ctx, DataFusion_GetQueryStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) FetchResult(ctx context.Context, in *FetchResultRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, DataFusion_FetchResult_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataFusionClient) EstimateCost(ctx context.Context, in *EstimateCostRequest, opts ...grpc.CallOption) (*CostEstimate, error) {
+	out := new(CostEstimate)
+	err := c.cc.Invoke(ctx, DataFusion_EstimateCost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataFusionServer is the server API for DataFusion service.
+// All implementations must embed UnimplementedDataFusionServer
+// for forward compatibility
+type DataFusionServer interface {
+	// 执行 SQL 查询并一次性返回结果
+	ExecuteQuery(context.Context, *QueryRequest) (*QueryResponse, error)
+	// 依次执行以分号分隔的多条语句，按顺序流式返回每条语句的结果，
+	// 某条语句失败时以该语句的错误结束流，其后的语句不再执行
+	ExecuteMulti(*QueryRequest, DataFusion_ExecuteMultiServer) error
+	// 执行 SQL 查询并按批次流式返回结果
+	QueryStream(*QueryRequest, DataFusion_QueryStreamServer) error
+	// 预编译带 $1、$2 占位符的语句
+	Prepare(context.Context, *PrepareRequest) (*PrepareResponse, error)
+	// 绑定参数执行预编译语句，句柄失效时返回 NOT_FOUND
+	ExecPrepared(context.Context, *ExecPreparedRequest) (*QueryResponse, error)
+	// 在一次往返中执行多条语句，单条失败不影响其余语句
+	ExecuteBatch(context.Context, *BatchRequest) (*BatchResponse, error)
+	// 列出目录中的表
+	ListTables(context.Context, *ListTablesRequest) (*ListTablesResponse, error)
+	// 返回表的列定义
+	DescribeTable(context.Context, *DescribeTableRequest) (*DescribeTableResponse, error)
+	// 返回集群当前的成员地址
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+	// 终止正在执行的查询
+	CancelQuery(context.Context, *CancelQueryRequest) (*CancelQueryResponse, error)
+	// 开启事务，返回的会话 ID 用于关联事务内的语句
+	BeginTransaction(context.Context, *BeginTransactionRequest) (*BeginTransactionResponse, error)
+	// 提交事务
+	CommitTransaction(context.Context, *EndTransactionRequest) (*EndTransactionResponse, error)
+	// 回滚事务
+	RollbackTransaction(context.Context, *EndTransactionRequest) (*EndTransactionResponse, error)
+	// 执行查询并返回第一页结果
+	OpenCursor(context.Context, *OpenCursorRequest) (*Page, error)
+	// 按续页令牌返回下一页，令牌未知或已过期时返回 NOT_FOUND
+	FetchPage(context.Context, *FetchPageRequest) (*Page, error)
+	// 释放游标在服务端的状态
+	CloseCursor(context.Context, *CloseCursorRequest) (*CloseCursorResponse, error)
+	// 提交查询后立即返回查询 ID，查询在服务端后台执行
+	SubmitQuery(context.Context, *SubmitQueryRequest) (*SubmitQueryResponse, error)
+	// 返回后台查询的执行状态，查询 ID 未知时返回 NOT_FOUND
+	GetQueryStatus(context.Context, *QueryStatusRequest) (*QueryStatusResponse, error)
+	// 返回已成功的后台查询的结果，尚未完成时返回 FAILED_PRECONDITION
+	FetchResult(context.Context, *FetchResultRequest) (*QueryResponse, error)
+	// 只生成执行计划并估算代价，不执行查询
+	EstimateCost(context.Context, *EstimateCostRequest) (*CostEstimate, error)
+	mustEmbedUnimplementedDataFusionServer()
+}
+
+// UnimplementedDataFusionServer must be embedded to have forward compatible implementations.
+type UnimplementedDataFusionServer struct {
+}
+
+func (UnimplementedDataFusionServer) ExecuteQuery(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteQuery not implemented")
+}
+func (UnimplementedDataFusionServer) ExecuteMulti(*QueryRequest, DataFusion_ExecuteMultiServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteMulti not implemented")
+}
+func (UnimplementedDataFusionServer) QueryStream(*QueryRequest, DataFusion_QueryStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryStream not implemented")
+}
+func (UnimplementedDataFusionServer) Prepare(context.Context, *PrepareRequest) (*PrepareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prepare not implemented")
+}
+func (UnimplementedDataFusionServer) ExecPrepared(context.Context, *ExecPreparedRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecPrepared not implemented")
+}
+func (UnimplementedDataFusionServer) ExecuteBatch(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteBatch not implemented")
+}
+func (UnimplementedDataFusionServer) ListTables(context.Context, *ListTablesRequest) (*ListTablesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTables not implemented")
+}
+func (UnimplementedDataFusionServer) DescribeTable(context.Context, *DescribeTableRequest) (*DescribeTableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeTable not implemented")
+}
+func (UnimplementedDataFusionServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedDataFusionServer) CancelQuery(context.Context, *CancelQueryRequest) (*CancelQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelQuery not implemented")
+}
+func (UnimplementedDataFusionServer) BeginTransaction(context.Context, *BeginTransactionRequest) (*BeginTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginTransaction not implemented")
+}
+func (UnimplementedDataFusionServer) CommitTransaction(context.Context, *EndTransactionRequest) (*EndTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitTransaction not implemented")
+}
+func (UnimplementedDataFusionServer) RollbackTransaction(context.Context, *EndTransactionRequest) (*EndTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackTransaction not implemented")
+}
+func (UnimplementedDataFusionServer) OpenCursor(context.Context, *OpenCursorRequest) (*Page, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OpenCursor not implemented")
+}
+func (UnimplementedDataFusionServer) FetchPage(context.Context, *FetchPageRequest) (*Page, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchPage not implemented")
+}
+func (UnimplementedDataFusionServer) CloseCursor(context.Context, *CloseCursorRequest) (*CloseCursorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseCursor not implemented")
+}
+func (UnimplementedDataFusionServer) SubmitQuery(context.Context, *SubmitQueryRequest) (*SubmitQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitQuery not implemented")
+}
+func (UnimplementedDataFusionServer) GetQueryStatus(context.Context, *QueryStatusRequest) (*QueryStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQueryStatus not implemented")
+}
+func (UnimplementedDataFusionServer) FetchResult(context.Context, *FetchResultRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchResult not implemented")
+}
+func (UnimplementedDataFusionServer) EstimateCost(context.Context, *EstimateCostRequest) (*CostEstimate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateCost not implemented")
+}
+func (UnimplementedDataFusionServer) mustEmbedUnimplementedDataFusionServer() {}
+
+// UnsafeDataFusionServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataFusionServer will
+// result in compilation errors.
+type UnsafeDataFusionServer interface {
+	mustEmbedUnimplementedDataFusionServer()
+}
+
+func RegisterDataFusionServer(s grpc.ServiceRegistrar, srv DataFusionServer) {
+	s.RegisterService(&DataFusion_ServiceDesc, srv)
+}
+
+func _DataFusion_ExecuteQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).ExecuteQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_ExecuteQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).ExecuteQuery(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_ExecuteMulti_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataFusionServer).ExecuteMulti(m, &dataFusionExecuteMultiServer{stream})
+}
+
+type DataFusion_ExecuteMultiServer interface {
+	Send(*QueryResponse) error
+	grpc.ServerStream
+}
+
+type dataFusionExecuteMultiServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataFusionExecuteMultiServer) Send(m *QueryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataFusion_QueryStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataFusionServer).QueryStream(m, &dataFusionQueryStreamServer{stream})
+}
+
+type DataFusion_QueryStreamServer interface {
+	Send(*RowBatch) error
+	grpc.ServerStream
+}
+
+type dataFusionQueryStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataFusionQueryStreamServer) Send(m *RowBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataFusion_Prepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).Prepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_Prepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).Prepare(ctx, req.(*PrepareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_ExecPrepared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecPreparedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).ExecPrepared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_ExecPrepared_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).ExecPrepared(ctx, req.(*ExecPreparedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_ExecuteBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).ExecuteBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_ExecuteBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).ExecuteBatch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_ListTables_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTablesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).ListTables(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_ListTables_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).ListTables(ctx, req.(*ListTablesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_DescribeTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).DescribeTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_DescribeTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).DescribeTable(ctx, req.(*DescribeTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_CancelQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).CancelQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_CancelQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).CancelQuery(ctx, req.(*CancelQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_BeginTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).BeginTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_BeginTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).BeginTransaction(ctx, req.(*BeginTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_CommitTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).CommitTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_CommitTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).CommitTransaction(ctx, req.(*EndTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_RollbackTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).RollbackTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_RollbackTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).RollbackTransaction(ctx, req.(*EndTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_OpenCursor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenCursorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).OpenCursor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_OpenCursor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).OpenCursor(ctx, req.(*OpenCursorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_FetchPage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchPageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).FetchPage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_FetchPage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).FetchPage(ctx, req.(*FetchPageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_CloseCursor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseCursorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).CloseCursor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_CloseCursor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).CloseCursor(ctx, req.(*CloseCursorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_SubmitQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).SubmitQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_SubmitQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).SubmitQuery(ctx, req.(*SubmitQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_GetQueryStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).GetQueryStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_GetQueryStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).GetQueryStatus(ctx, req.(*QueryStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_FetchResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).FetchResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_FetchResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).FetchResult(ctx, req.(*FetchResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataFusion_EstimateCost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateCostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataFusionServer).EstimateCost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataFusion_EstimateCost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataFusionServer).EstimateCost(ctx, req.(*EstimateCostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataFusion_ServiceDesc is the grpc.ServiceDesc for DataFusion service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataFusion_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "datafusion.DataFusion",
+	HandlerType: (*DataFusionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExecuteQuery",
+			Handler:    _DataFusion_ExecuteQuery_Handler,
+		},
+		{
+			MethodName: "Prepare",
+			Handler:    _DataFusion_Prepare_Handler,
+		},
+		{
+			MethodName: "ExecPrepared",
+			Handler:    _DataFusion_ExecPrepared_Handler,
+		},
+		{
+			MethodName: "ExecuteBatch",
+			Handler:    _DataFusion_ExecuteBatch_Handler,
+		},
+		{
+			MethodName: "ListTables",
+			Handler:    _DataFusion_ListTables_Handler,
+		},
+		{
+			MethodName: "DescribeTable",
+			Handler:    _DataFusion_DescribeTable_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _DataFusion_ListMembers_Handler,
+		},
+		{
+			MethodName: "CancelQuery",
+			Handler:    _DataFusion_CancelQuery_Handler,
+		},
+		{
+			MethodName: "BeginTransaction",
+			Handler:    _DataFusion_BeginTransaction_Handler,
+		},
+		{
+			MethodName: "CommitTransaction",
+			Handler:    _DataFusion_CommitTransaction_Handler,
+		},
+		{
+			MethodName: "RollbackTransaction",
+			Handler:    _DataFusion_RollbackTransaction_Handler,
+		},
+		{
+			MethodName: "OpenCursor",
+			Handler:    _DataFusion_OpenCursor_Handler,
+		},
+		{
+			MethodName: "FetchPage",
+			Handler:    _DataFusion_FetchPage_Handler,
+		},
+		{
+			MethodName: "CloseCursor",
+			Handler:    _DataFusion_CloseCursor_Handler,
+		},
+		{
+			MethodName: "SubmitQuery",
+			Handler:    _DataFusion_SubmitQuery_Handler,
+		},
+		{
+			MethodName: "GetQueryStatus",
+			Handler:    _DataFusion_GetQueryStatus_Handler,
+		},
+		{
+			MethodName: "FetchResult",
+			Handler:    _DataFusion_FetchResult_Handler,
+		},
+		{
+			MethodName: "EstimateCost",
+			Handler:    _DataFusion_EstimateCost_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteMulti",
+			Handler:       _DataFusion_ExecuteMulti_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "QueryStream",
+			Handler:       _DataFusion_QueryStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "datafusion.proto",
+}